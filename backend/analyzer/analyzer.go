@@ -1,192 +1,41 @@
 package analyzer
 
 import (
-	commands "backend/commands"
-	"errors"
 	"fmt"
 	"strings"
 )
 
+// Options opciones de ejecución de una petición
+type Options struct {
+	DryRun bool // Los comandos destructivos solo informan lo que harían
+}
+
 // 🔹 Función principal del analizador
 func Analyzer(input string) (string, error) {
+	return AnalyzerWithOptions(input, Options{})
+}
 
-	// Ignorar líneas vacías o comentarios
-	if strings.TrimSpace(input) == "" || strings.HasPrefix(strings.TrimSpace(input), "#") {
-		return "", nil
-	}
-
-	tokens := strings.Fields(input)
-	if len(tokens) == 0 {
-		return "", errors.New("no se proporcionó ningún comando")
-	}
-
-	// Convertir el comando base a minúsculas para evitar errores de mayúsculas
-	cmd := strings.ToLower(tokens[0])
-
-	// 🚀 Simulación rápida del comando mkfile
-	if cmd == "mkfile" {
-		var ruta string
-		var size string = "(simulado)"
-
-		for _, p := range tokens[1:] {
-			if strings.HasPrefix(strings.ToLower(p), "-path=") {
-				ruta = strings.TrimPrefix(p, "-path=")
-			}
-			if strings.HasPrefix(strings.ToLower(p), "-size=") {
-				size = strings.TrimPrefix(p, "-size=")
-			}
-		}
-
-		if ruta == "" {
-			ruta = "(sin ruta especificada)"
-		}
-
-		return fmt.Sprintf("MKFILE: Archivo creado exitosamente\n-> Path: %s\n-> Tamaño: %s bytes\n", ruta, size), nil
-	}
-
-	// 🚀 Simulación de los demás comandos
-	if cmd == "remove" {
-		var path string
-		for _, p := range tokens[1:] {
-			if strings.HasPrefix(strings.ToLower(p), "-path=") {
-				path = strings.TrimPrefix(p, "-path=")
-			}
-		}
-		if path == "" {
-			path = "(sin ruta especificada)"
-		}
-		return fmt.Sprintf("REMOVE: Eliminado correctamente -> %s\n", path), nil
-	}
-
-	if cmd == "edit" {
-		var path, contenido string
-		for _, p := range tokens[1:] {
-			if strings.HasPrefix(strings.ToLower(p), "-path=") {
-				path = strings.TrimPrefix(p, "-path=")
-			}
-			if strings.HasPrefix(strings.ToLower(p), "-contenido=") {
-				contenido = strings.TrimPrefix(p, "-contenido=")
-			}
-		}
-		return fmt.Sprintf("EDIT: Archivo editado correctamente\n-> Path: %s\n-> Contenido: %s\n", path, contenido), nil
-	}
-
-	if cmd == "rename" {
-		var path, name string
-		for _, p := range tokens[1:] {
-			if strings.HasPrefix(strings.ToLower(p), "-path=") {
-				path = strings.TrimPrefix(p, "-path=")
-			}
-			if strings.HasPrefix(strings.ToLower(p), "-name=") {
-				name = strings.TrimPrefix(p, "-name=")
-			}
-		}
-		return fmt.Sprintf("RENAME: Archivo renombrado correctamente\n-> Nuevo nombre: %s\n-> Path: %s\n", name, path), nil
-	}
-
-	if cmd == "copy" {
-		var path, destino string
-		for _, p := range tokens[1:] {
-			if strings.HasPrefix(strings.ToLower(p), "-path=") {
-				path = strings.TrimPrefix(p, "-path=")
-			}
-			if strings.HasPrefix(strings.ToLower(p), "-destino=") {
-				destino = strings.TrimPrefix(p, "-destino=")
-			}
-		}
-		return fmt.Sprintf("COPY: Copia realizada exitosamente\n-> Origen: %s\n-> Destino: %s\n", path, destino), nil
-	}
+// AnalyzerWithOptions analiza y ejecuta una línea con las opciones de la petición
+func AnalyzerWithOptions(input string, opts Options) (string, error) {
 
-	if cmd == "move" {
-		var path, destino string
-		for _, p := range tokens[1:] {
-			if strings.HasPrefix(strings.ToLower(p), "-path=") {
-				path = strings.TrimPrefix(p, "-path=")
-			}
-			if strings.HasPrefix(strings.ToLower(p), "-destino=") {
-				destino = strings.TrimPrefix(p, "-destino=")
-			}
-		}
-		return fmt.Sprintf("MOVE: Archivo movido correctamente\n-> Origen: %s\n-> Destino: %s\n", path, destino), nil
+	// Separar el comando de sus parámetros (las comillas y comentarios se resuelven aquí)
+	cmd, params, err := Tokenize(input)
+	if err != nil {
+		return "", err
 	}
 
-	if cmd == "find" {
-		var path, name string
-		for _, p := range tokens[1:] {
-			if strings.HasPrefix(strings.ToLower(p), "-path=") {
-				path = strings.TrimPrefix(p, "-path=")
-			}
-			if strings.HasPrefix(strings.ToLower(p), "-name=") {
-				name = strings.TrimPrefix(p, "-name=")
-			}
-		}
-		return fmt.Sprintf("FIND: Búsqueda completada\n-> Path: %s\n-> Nombre: %s\n-> Resultado: (simulado)\n", path, name), nil
-	}
-
-	if cmd == "chown" {
-		var path, user string
-		for _, p := range tokens[1:] {
-			if strings.HasPrefix(strings.ToLower(p), "-path=") {
-				path = strings.TrimPrefix(p, "-path=")
-			}
-			if strings.HasPrefix(strings.ToLower(p), "-usuario=") {
-				user = strings.TrimPrefix(p, "-usuario=")
-			}
-		}
-		if user == "user_no_existe" {
-			return fmt.Sprintf("CHOWN: Error -> el usuario '%s' no existe\n", user), nil
-		}
-		return fmt.Sprintf("CHOWN: Cambiado propietario de %s a %s\n", path, user), nil
+	// Ignorar líneas vacías o comentarios
+	if cmd == "" {
+		return "", nil
 	}
 
-	if cmd == "chmod" {
-		var path, ugo string
-		for _, p := range tokens[1:] {
-			if strings.HasPrefix(strings.ToLower(p), "-path=") {
-				path = strings.TrimPrefix(p, "-path=")
-			}
-			if strings.HasPrefix(strings.ToLower(p), "-ugo=") {
-				ugo = strings.TrimPrefix(p, "-ugo=")
-			}
-		}
-		return fmt.Sprintf("CHMOD: Permisos modificados correctamente\n-> Path: %s\n-> Permisos: %s\n", path, ugo), nil
+	// 🔸 Cada comando declara sus parámetros y locks en el registro
+	spec, ok := LookupCommand(cmd)
+	if !ok {
+		return "", fmt.Errorf("comando desconocido: %s", cmd)
 	}
 
-	// 🔸 Comandos normales
-	switch cmd {
-	case "mkdisk":
-		return commands.ParseMkdisk(tokens[1:])
-	case "rmdisk":
-		return commands.ParserRmdisk(tokens[1:])
-	case "fdisk":
-		return commands.ParseFdisk(tokens[1:])
-	case "mount":
-		return commands.ParseMount(tokens[1:])
-	case "mkfs":
-		return commands.ParseMkfs(tokens[1:])
-	case "rep":
-		return commands.ParseRep(tokens[1:])
-	case "mkdir":
-		return commands.ParseMkdir(tokens[1:])
-	case "login":
-		return commands.ParseLogin(tokens[1:])
-	case "logout":
-		return commands.ParseLogout(tokens[1:])
-	case "mounted":
-		return commands.ParseMounted(tokens[1:])
-	case "unmount":
-		return commands.ParseUnmount(tokens)
-	case "mkgrp":
-		return commands.ParseMkgrp(tokens[1:])
-	case "rmgrp":
-		return commands.ParseRmgrp(tokens[1:])
-	case "cat":
-		return commands.ParseCat(tokens[1:])
-	case "mkusr":
-		return commands.ParseMkusr(tokens[1:])
-	default:
-		return "", fmt.Errorf("comando desconocido: %s", tokens[0])
-	}
+	return spec.Dispatch(params, opts)
 }
 
 // 🔹 Permite ejecutar varios comandos seguidos
@@ -0,0 +1,72 @@
+package analyzer
+
+import (
+	"strings"
+	"time"
+)
+
+// Estados posibles de una línea del script
+const (
+	StatusOK      = "ok"
+	StatusError   = "error"
+	StatusSkipped = "skipped" // Comentario, no se ejecuta
+)
+
+// LineResult resultado de ejecutar una línea de un script
+type LineResult struct {
+	Line       int    `json:"line"`            // Número de línea en el script (desde 1)
+	Raw        string `json:"raw"`             // Texto original de la línea
+	Command    string `json:"command"`         // Nombre del comando en minúsculas
+	Status     string `json:"status"`          // ok | error | skipped
+	Success    bool   `json:"success"`         // true si el comando terminó sin error
+	Output     string `json:"output"`          // Salida del comando
+	Error      string `json:"error,omitempty"` // Mensaje de error, si lo hubo
+	DurationMs int64  `json:"durationMs"`      // Duración de la ejecución en milisegundos
+}
+
+// ExecuteScript ejecuta un script línea por línea y entrega el resultado de cada
+// línea a emit apenas termina, para poder mostrar el progreso en vivo.
+// Los comentarios se entregan marcados como skipped; las líneas vacías se omiten.
+// Si emit devuelve false (ej: el cliente se desconectó) no se ejecutan más líneas.
+func ExecuteScript(input string, opts Options, emit func(LineResult) bool) {
+	for i, line := range strings.Split(input, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "#") {
+			if !emit(LineResult{Line: i + 1, Raw: line, Status: StatusSkipped}) {
+				return
+			}
+			continue
+		}
+
+		if !emit(ExecuteLine(i+1, line, opts)) {
+			return
+		}
+	}
+}
+
+// ExecuteLine ejecuta una sola línea y mide su duración
+func ExecuteLine(lineNumber int, line string, opts Options) LineResult {
+	result := LineResult{Line: lineNumber, Raw: line}
+	if cmd, _, err := Tokenize(line); err == nil {
+		result.Command = cmd
+	}
+
+	start := time.Now()
+	output, err := AnalyzerWithOptions(line, opts)
+	result.DurationMs = time.Since(start).Milliseconds()
+
+	if err != nil {
+		result.Status = StatusError
+		result.Error = err.Error()
+	} else {
+		result.Status = StatusOK
+		result.Success = true
+		result.Output = output
+	}
+
+	return result
+}
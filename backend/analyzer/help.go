@@ -0,0 +1,44 @@
+package analyzer
+
+import (
+	utils "backend/utils"
+	"fmt"
+	"strings"
+)
+
+// helpCommand lista los comandos registrados o la ayuda detallada de uno
+var helpCommand = &CommandSpec{
+	Name:        "help",
+	Description: "Muestra los comandos disponibles y sus parámetros",
+	Params: []ParamSpec{
+		{Name: "cmd", Type: ParamString, Positional: true, Description: "Comando del que se quiere ver la ayuda"},
+	},
+	run: runHelp,
+}
+
+/*
+	help
+	help mkdisk
+*/
+
+func runHelp(params utils.Params) (string, error) {
+	if name, ok := params.Get("cmd"); ok {
+		spec, found := LookupCommand(name)
+		if !found {
+			return "", fmt.Errorf("comando desconocido: %s", name)
+		}
+		return spec.Help(), nil
+	}
+
+	var b strings.Builder
+	b.WriteString("HELP: Comandos disponibles")
+	for _, spec := range Commands() {
+		fmt.Fprintf(&b, "\n  %-8s %s", spec.Name, spec.Description)
+		if spec.Simulated {
+			b.WriteString(" (simulado)")
+		}
+		fmt.Fprintf(&b, "\n           %s", spec.Usage())
+	}
+	b.WriteString("\nUse help <comando> para ver el detalle de sus parámetros")
+	return b.String(), nil
+}
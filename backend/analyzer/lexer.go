@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	utils "backend/utils"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Tokenize divide una línea en el nombre del comando (en minúsculas) y sus parámetros.
+//
+//	mkdir -p -path="/home/mis documentos/x"   # comentario
+//
+// Soporta valores entre comillas rectas o tipográficas (“ ”) con espacios,
+// comentarios con # fuera de comillas y claves sin distinguir mayúsculas.
+func Tokenize(line string) (string, utils.Params, error) {
+	words, err := splitWords(line)
+	if err != nil {
+		return "", nil, err
+	}
+
+	params := utils.Params{}
+	if len(words) == 0 {
+		return "", params, nil
+	}
+
+	cmd := strings.ToLower(words[0])
+
+	positional := 0
+	for i, word := range words[1:] {
+		// Argumento sin guion (ej: help mkdisk); el registro decide si el comando lo acepta
+		if !strings.HasPrefix(word, "-") {
+			positional++
+			key := fmt.Sprintf("arg%d", positional)
+			params[key] = utils.Param{Key: key, Value: word, Positional: true, Position: i}
+			continue
+		}
+
+		if len(word) == 1 {
+			return "", nil, fmt.Errorf("parámetro inválido: %s", word)
+		}
+
+		key, value, hasValue := strings.Cut(word[1:], "=")
+		key = strings.ToLower(key)
+		if key == "" {
+			return "", nil, fmt.Errorf("parámetro inválido: %s", word)
+		}
+
+		params[key] = utils.Param{
+			Key:      key,
+			Value:    value,
+			IsFlag:   !hasValue,
+			Position: i,
+		}
+	}
+
+	return cmd, params, nil
+}
+
+// splitWords separa la línea por espacios respetando las comillas y descartando
+// el comentario final. Las comillas se eliminan del resultado.
+func splitWords(line string) ([]string, error) {
+	var words []string
+	var current strings.Builder
+	inWord := false
+	var closing []rune // Comillas que cierran la cita actual (nil fuera de comillas)
+
+	for _, r := range line {
+		switch {
+		case closing != nil:
+			if containsRune(closing, r) {
+				closing = nil
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"':
+			closing = []rune{'"'}
+			inWord = true
+		case r == '“' || r == '”':
+			closing = []rune{'”', '“'}
+			inWord = true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		case r == '#' && !inWord:
+			// Comentario: el resto de la línea se ignora
+			return words, nil
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if closing != nil {
+		return nil, errors.New("comillas sin cerrar en la línea")
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+
+	return words, nil
+}
+
+func containsRune(runes []rune, r rune) bool {
+	for _, c := range runes {
+		if c == r {
+			return true
+		}
+	}
+	return false
+}
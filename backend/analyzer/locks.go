@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	stores "backend/stores"
+	utils "backend/utils"
+	"strings"
+	"sync"
+)
+
+// access indica el tipo de acceso de un comando a un recurso compartido
+type access int
+
+const (
+	accessNone  access = iota // No usa el recurso
+	accessRead                // Solo lectura (puede ejecutarse en paralelo)
+	accessWrite               // Lectura-modificación-escritura (exclusivo)
+)
+
+// diskTarget indica cómo se obtiene el disco que usa un comando
+type diskTarget int
+
+const (
+	targetNone    diskTarget = iota // El comando no usa ningún disco
+	targetPath                      // Parámetro -path
+	targetID                        // Parámetro -id (partición montada)
+	targetSession                   // Partición de la sesión activa
+)
+
+// lockSpec describe los locks que necesita un comando durante toda su ejecución
+type lockSpec struct {
+	state  access
+	disk   access
+	target diskTarget
+}
+
+// acquireLocks toma los locks del comando (primero el de estado, luego el del disco) y
+// devuelve el disco bloqueado ("" si no tomó ninguno) y la función que los libera en
+// orden inverso
+func acquireLocks(spec lockSpec, params utils.Params) (string, func()) {
+	var releases []func()
+	lock := func(l *sync.RWMutex, a access) {
+		switch a {
+		case accessRead:
+			l.RLock()
+			releases = append(releases, l.RUnlock)
+		case accessWrite:
+			l.Lock()
+			releases = append(releases, l.Unlock)
+		}
+	}
+
+	lock(&stores.StateLock, spec.state)
+
+	// El disco se resuelve con el lock de estado tomado, así los montajes no cambian
+	diskPath := ""
+	if spec.disk != accessNone {
+		diskPath = resolveDiskPath(spec.target, params)
+	}
+	if diskPath != "" {
+		lock(stores.DiskLock(diskPath), spec.disk)
+	}
+
+	return diskPath, func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}
+}
+
+// resolveDiskPath obtiene la ruta del disco que usará el comando
+func resolveDiskPath(target diskTarget, params utils.Params) string {
+	switch target {
+	case targetPath:
+		return params.Value("path")
+	case targetID:
+		return stores.MountedPartitions[strings.ToUpper(params.Value("id"))]
+	case targetSession:
+		if !stores.Auth.IsAuthenticated() {
+			return ""
+		}
+		return stores.MountedPartitions[strings.ToUpper(stores.Auth.GetPartitionID())]
+	}
+	return ""
+}
@@ -0,0 +1,173 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	stores "backend/stores"
+)
+
+// run ejecuta una línea y falla el test si el comando devuelve error
+func run(t *testing.T, line string) string {
+	t.Helper()
+	out, err := Analyzer(line)
+	if err != nil {
+		t.Fatalf("%s: %v", line, err)
+	}
+	return out
+}
+
+// mount monta la partición y devuelve el ID que le asignó mount
+func mount(t *testing.T, disk, name string) string {
+	t.Helper()
+	out := run(t, "mount -path="+disk+" -name="+name)
+	match := regexp.MustCompile(`ID: (\S+)`).FindStringSubmatch(out)
+	if match == nil {
+		t.Fatalf("mount no informó el ID: %q", out)
+	}
+	return match[1]
+}
+
+// useTempConfig evita que los tests escriban letras o discos fuera de una carpeta temporal
+func useTempConfig(t *testing.T) {
+	t.Helper()
+	saved := *stores.Config
+	t.Cleanup(func() { *stores.Config = saved })
+	stores.Config.DiskRoot = ""
+	stores.Config.LettersFile = filepath.Join(t.TempDir(), "letters.json")
+}
+
+func TestMemDiskRoundTrip(t *testing.T) {
+	useTempConfig(t)
+	disk := filepath.Join(t.TempDir(), "roundtrip.mia")
+
+	run(t, "mkdisk -mem -size=1 -unit=M -path="+disk)
+	if _, err := os.Stat(disk); !os.IsNotExist(err) {
+		t.Fatalf("mkdisk -mem creó un archivo en %s", disk)
+	}
+	run(t, "fdisk -size=300 -unit=K -path="+disk+" -name=P1")
+
+	id := mount(t, disk, "P1")
+
+	run(t, "mkfs -id="+id+" -fs=3fs")
+	if out := run(t, "layout -id="+id); !strings.Contains(out, "EXT3") {
+		t.Errorf("layout no muestra EXT3: %q", out)
+	}
+
+	run(t, "login -user=root -pass=123 -id="+id)
+	run(t, "mkgrp -name=devs")
+	users := run(t, "cat -file1=/users.txt")
+	run(t, "logout")
+	if !strings.Contains(users, "1,G,root") || !strings.Contains(users, "2,G,devs") {
+		t.Errorf("users.txt inesperado después de mkgrp: %q", users)
+	}
+
+	run(t, "unmount -id="+id)
+	run(t, "rmdisk -path="+disk)
+	if stores.IsMemDevice(disk) {
+		t.Errorf("rmdisk no descartó el disco en memoria")
+	}
+}
+
+func TestMemDiskLimit(t *testing.T) {
+	useTempConfig(t)
+	stores.Config.MemLimit = 1024 * 1024
+	disk := filepath.Join(t.TempDir(), "big.mia")
+
+	if _, err := Analyzer("mkdisk -mem -size=2 -unit=M -path=" + disk); err == nil {
+		t.Fatalf("mkdisk -mem aceptó un disco más grande que MIA_MEM_LIMIT_MB")
+	}
+	if stores.IsMemDevice(disk) {
+		t.Errorf("el disco rechazado quedó registrado en memoria")
+	}
+
+	run(t, "mkdisk -mem -size=1 -unit=M -path="+disk)
+	dev, err := stores.OpenDevice(disk)
+	if err != nil {
+		t.Fatalf("no se pudo abrir el disco en memoria: %v", err)
+	}
+	if _, err := dev.WriteAt([]byte{1}, dev.Size()); err == nil || dev.Size() != 1024*1024 {
+		t.Errorf("escribir después del final agrandó el disco en memoria a %d bytes", dev.Size())
+	}
+	run(t, "rmdisk -path="+disk)
+}
+
+func TestMemDiskDeleteUndo(t *testing.T) {
+	useTempConfig(t)
+	disk := filepath.Join(t.TempDir(), "undo.mia")
+
+	run(t, "mkdisk -mem -size=1 -unit=M -path="+disk)
+	run(t, "fdisk -size=300 -unit=K -path="+disk+" -name=P1")
+	run(t, "fdisk -size=300 -unit=K -path="+disk+" -name=P2")
+
+	id := mount(t, disk, "P1")
+	run(t, "mkfs -id="+id)
+	run(t, "login -user=root -pass=123 -id="+id)
+	run(t, "mkgrp -name=devs")
+	run(t, "logout")
+
+	// Deshacer no puede quitarle el ID a una partición montada
+	run(t, "fdisk -size=100 -unit=K -path="+disk+" -name=P3")
+	other := mount(t, disk, "P3")
+	if _, err := Analyzer("fdisk -undo -path=" + disk); err == nil {
+		t.Fatalf("fdisk -undo deshizo la creación de una partición montada")
+	}
+	run(t, "unmount -id="+other)
+	run(t, "fdisk -undo -path="+disk)
+
+	run(t, "unmount -id="+id)
+	run(t, "fdisk -delete=fast -path="+disk+" -name=P1")
+	if _, err := Analyzer("mount -path=" + disk + " -name=P1"); err == nil {
+		t.Fatalf("se montó una partición eliminada")
+	}
+	run(t, "fdisk -undo -path="+disk)
+
+	// La tabla restaurada apunta al mismo sistema de archivos
+	id = mount(t, disk, "P1")
+	run(t, "login -user=root -pass=123 -id="+id)
+	users := run(t, "cat -file1=/users.txt")
+	run(t, "logout")
+	if !strings.Contains(users, "2,G,devs") {
+		t.Errorf("users.txt inesperado después de deshacer: %q", users)
+	}
+
+	// Con delete=full los datos ya no existen: no se puede deshacer
+	run(t, "fdisk -delete=full -force -path="+disk+" -name=P1")
+	if _, err := Analyzer("fdisk -undo -path=" + disk); err == nil {
+		t.Errorf("fdisk -undo restauró una partición llenada con ceros")
+	}
+}
+
+func TestMemDiskResizeFilesystem(t *testing.T) {
+	useTempConfig(t)
+	disk := filepath.Join(t.TempDir(), "resize.mia")
+
+	run(t, "mkdisk -mem -size=1 -unit=M -path="+disk)
+	run(t, "fdisk -size=300 -unit=K -path="+disk+" -name=P1")
+	id := mount(t, disk, "P1")
+	run(t, "mkfs -id="+id+" -fs=3fs")
+	run(t, "login -user=root -pass=123 -id="+id)
+	run(t, "mkgrp -name=devs")
+	run(t, "logout")
+	before := run(t, "layout -id="+id)
+
+	// Agrandar y reducir reubica los bitmaps, los inodos y los bloques
+	for _, add := range []string{"200", "-350"} {
+		if out := run(t, "fdisk -add="+add+" -unit=K -path="+disk+" -name=P1"); !strings.Contains(out, "EXT3:") {
+			t.Errorf("fdisk -add=%s no reubicó el sistema de archivos: %q", add, out)
+		}
+		if out := run(t, "layout -id="+id); out == before || strings.Contains(out, "AVISO") {
+			t.Errorf("layout inesperado después de fdisk -add=%s: %q", add, out)
+		}
+
+		run(t, "login -user=root -pass=123 -id="+id)
+		users := run(t, "cat -file1=/users.txt")
+		run(t, "logout")
+		if !strings.Contains(users, "1,U,root,root,") || !strings.Contains(users, "2,G,devs") {
+			t.Errorf("users.txt inesperado después de fdisk -add=%s: %q", add, users)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	structures "backend/structures"
+)
+
+// writeV1Disk arma un disco v1 de 1 MB con la partición P1 formateada en EXT2 como lo
+// hacía mkfs antes del formato v2: root con users.txt, inodos de 128 bytes y bitmaps
+// de '0'/'1'
+func writeV1Disk(t *testing.T, path, usersText string) {
+	t.Helper()
+	const (
+		diskSize  = 1024 * 1024
+		partStart = 153 // Tamaño del MBR v1
+		partSize  = 300 * 1024
+		inodes    = 16
+		inodeSlot = 128
+		blockSize = 64
+	)
+	image := make([]byte, diskSize)
+	put := func(offset int64, data any) {
+		if _, err := binary.Encode(image[offset:], binary.LittleEndian, data); err != nil {
+			t.Fatalf("no se pudo armar el disco v1: %v", err)
+		}
+	}
+
+	mbr := structures.MBRV1{Mbr_size: diskSize, Mbr_creation_date: 1.7e9, Mbr_disk_signature: 4501, Mbr_disk_fit: [1]byte{'F'}}
+	for i := range mbr.Mbr_partitions {
+		mbr.Mbr_partitions[i] = structures.PartitionV1{Part_status: [1]byte{'0'}, Part_start: -1, Part_correlative: -1}
+	}
+	p1 := &mbr.Mbr_partitions[0]
+	p1.Part_type, p1.Part_fit = [1]byte{'P'}, [1]byte{'W'}
+	p1.Part_start, p1.Part_size = partStart, partSize
+	copy(p1.Part_name[:], "P1")
+	put(0, &mbr)
+
+	sb := structures.SuperBlockV1{
+		S_filesystem_type:   2,
+		S_inodes_count:      2,
+		S_blocks_count:      2,
+		S_free_inodes_count: inodes - 2,
+		S_free_blocks_count: 3*inodes - 2,
+		S_mtime:             1.7e9,
+		S_mnt_count:         1,
+		S_magic:             0xEF53,
+		S_inode_size:        inodeSlot,
+		S_block_size:        blockSize,
+	}
+	sb.S_bm_inode_start = partStart + int32(binary.Size(sb))
+	sb.S_bm_block_start = sb.S_bm_inode_start + inodes
+	sb.S_inode_start = sb.S_bm_block_start + 3*inodes
+	sb.S_block_start = sb.S_inode_start + inodes*inodeSlot
+	sb.S_first_ino = sb.S_inode_start + 2*inodeSlot
+	sb.S_first_blo = sb.S_block_start + 2*blockSize
+	put(partStart, &sb)
+
+	copy(image[sb.S_bm_inode_start:], bytes.Repeat([]byte{'0'}, inodes))
+	copy(image[sb.S_bm_block_start:], bytes.Repeat([]byte{'0'}, 3*inodes))
+	copy(image[sb.S_bm_inode_start:], "11")
+	copy(image[sb.S_bm_block_start:], "11")
+
+	noBlocks := [15]int32{}
+	for i := range noBlocks {
+		noBlocks[i] = -1
+	}
+	root := structures.InodeV1{I_uid: 1, I_gid: 1, I_atime: 1.7e9, I_ctime: 1.7e9, I_mtime: 1.7e9, I_block: noBlocks, I_type: [1]byte{'0'}, I_perm: [3]byte{'7', '7', '7'}}
+	root.I_block[0] = 0
+	users := root
+	users.I_size = int32(len(usersText))
+	users.I_block[0] = 1
+	users.I_type = [1]byte{'1'}
+	put(int64(sb.S_inode_start), &root)
+	put(int64(sb.S_inode_start+inodeSlot), &users)
+
+	folder := structures.FolderBlock{B_content: [4]structures.FolderContent{
+		{B_name: [12]byte{'.'}, B_inodo: 0},
+		{B_name: [12]byte{'.', '.'}, B_inodo: 0},
+		{B_name: [12]byte{'u', 's', 'e', 'r', 's', '.', 't', 'x', 't'}, B_inodo: 1},
+		{B_name: [12]byte{'-'}, B_inodo: -1},
+	}}
+	put(int64(sb.S_block_start), &folder)
+	copy(image[sb.S_block_start+blockSize:], usersText)
+
+	if err := os.WriteFile(path, image, 0o644); err != nil {
+		t.Fatalf("no se pudo escribir el disco v1: %v", err)
+	}
+}
+
+func TestMigrateV1Disk(t *testing.T) {
+	useTempConfig(t)
+	disk := filepath.Join(t.TempDir(), "v1.mia")
+	writeV1Disk(t, disk, "1,G,root\n1,U,root,root,123\n2,G,devs\n")
+
+	if out := run(t, "migrate -dryrun -path="+disk); !strings.Contains(out, "EXT2") {
+		t.Errorf("migrate -dryrun no informó la partición formateada: %q", out)
+	}
+	out := run(t, "migrate -path="+disk)
+	if !strings.Contains(out, "Partición 'P1'") {
+		t.Errorf("migrate no informó la conversión de P1: %q", out)
+	}
+	if out := run(t, "migrate -path="+disk); !strings.Contains(out, "ya está en el formato") {
+		t.Errorf("migrate volvió a convertir un disco v2: %q", out)
+	}
+
+	// El disco convertido se usa como cualquier disco v2
+	id := mount(t, disk, "P1")
+	if out := run(t, "layout -id="+id); !strings.Contains(out, "EXT2") || strings.Contains(out, "AVISO") {
+		t.Errorf("layout inesperado después de migrar: %q", out)
+	}
+	run(t, "login -user=root -pass=123 -id="+id)
+	run(t, "mkgrp -name=ops")
+	users := run(t, "cat -file1=/users.txt")
+	run(t, "logout")
+	if !strings.Contains(users, "2,G,devs") || !strings.Contains(users, "3,G,ops") {
+		t.Errorf("users.txt inesperado después de migrar: %q", users)
+	}
+
+	run(t, "fdisk -size=100 -unit=K -path="+disk+" -name=P2")
+	run(t, "unmount -id="+id)
+	run(t, "rmdisk -path="+disk)
+}
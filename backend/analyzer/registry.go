@@ -0,0 +1,574 @@
+package analyzer
+
+import (
+	commands "backend/commands"
+	stores "backend/stores"
+	utils "backend/utils"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParamType tipo de valor que acepta un parámetro
+type ParamType string
+
+const (
+	ParamString ParamType = "string" // Texto libre
+	ParamInt    ParamType = "int"    // Número entero (puede ser negativo)
+	ParamFlag   ParamType = "flag"   // Bandera sin valor (ej: -p)
+	ParamPath   ParamType = "path"   // Ruta de disco o reporte, confinada a la raíz de discos
+)
+
+// ParamSpec declaración de un parámetro de un comando
+type ParamSpec struct {
+	Name        string    `json:"name"`              // Nombre en minúsculas, sin el guion
+	Type        ParamType `json:"type"`              // Tipo de valor
+	Required    bool      `json:"required"`          // Obligatorio
+	Allowed     []string  `json:"allowed,omitempty"` // Valores permitidos (sin distinguir mayúsculas); vacío = cualquiera
+	Default     string    `json:"default,omitempty"` // Valor por defecto si no se proporciona
+	Numbered    bool      `json:"numbered"`          // Se escribe como -nombreN (ej: -file1, -file2)
+	Positional  bool      `json:"positional"`        // Se escribe sin guion ni clave (ej: help mkdisk)
+	Description string    `json:"description"`       // Descripción para la ayuda
+}
+
+// CommandSpec declaración de un comando: sus parámetros, locks y la función que lo ejecuta
+type CommandSpec struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Params      []ParamSpec `json:"params"`
+	Simulated   bool        `json:"simulated"` // El comando solo simula su salida, no modifica el disco
+	locks       lockSpec
+	run         func(utils.Params) (string, error)
+}
+
+// commandSpecs comandos registrados, en el orden en que se muestran en la ayuda
+var commandSpecs = []*CommandSpec{
+	{
+		Name:        "mkdisk",
+		Description: "Crea un disco virtual",
+		Params: []ParamSpec{
+			{Name: "size", Type: ParamInt, Required: true, Description: "Tamaño del disco"},
+			{Name: "unit", Type: ParamString, Allowed: []string{"K", "M"}, Default: "M", Description: "Unidad del tamaño"},
+			{Name: "fit", Type: ParamString, Allowed: []string{"BF", "FF", "WF"}, Default: "FF", Description: "Ajuste de las particiones"},
+			{Name: "path", Type: ParamPath, Required: true, Description: "Ruta absoluta del disco"},
+			{Name: "alloc", Type: ParamString, Allowed: []string{"full", "sparse"}, Default: "full", Description: "Reserva del espacio: full escribe ceros, sparse solo fija el tamaño"},
+			{Name: "mem", Type: ParamFlag, Description: "Crea el disco solo en memoria; se pierde al terminar el servidor"},
+		},
+		locks: lockSpec{state: accessRead, disk: accessWrite, target: targetPath},
+		run:   commands.ParseMkdisk,
+	},
+	{
+		Name:        "rmdisk",
+		Description: "Elimina un disco virtual",
+		Params: []ParamSpec{
+			{Name: "path", Type: ParamPath, Required: true, Description: "Ruta del disco"},
+			{Name: "force", Type: ParamFlag, Description: "Desmonta las particiones del disco y cierra su sesión"},
+			{Name: "dryrun", Type: ParamFlag, Description: "Solo informa lo que cambiaría, sin escribir en el disco"},
+		},
+		locks: lockSpec{state: accessWrite, disk: accessWrite, target: targetPath},
+		run:   commands.ParserRmdisk,
+	},
+	{
+		Name:        "fdisk",
+		Description: "Crea, elimina o redimensiona particiones",
+		Params: []ParamSpec{
+			{Name: "size", Type: ParamInt, Description: "Tamaño de la partición (obligatorio al crear)"},
+			{Name: "unit", Type: ParamString, Allowed: []string{"B", "K", "M"}, Default: "K", Description: "Unidad del tamaño"},
+			{Name: "fit", Type: ParamString, Allowed: []string{"BF", "FF", "WF"}, Default: "WF", Description: "Ajuste de la partición"},
+			{Name: "path", Type: ParamPath, Required: true, Description: "Ruta del disco"},
+			{Name: "type", Type: ParamString, Allowed: []string{"P", "E", "L"}, Default: "P", Description: "Tipo de partición"},
+			{Name: "name", Type: ParamString, Description: "Nombre de la partición (obligatorio salvo con -undo)"},
+			{Name: "delete", Type: ParamString, Allowed: []string{"fast", "full"}, Description: "Elimina la partición"},
+			{Name: "add", Type: ParamInt, Description: "Espacio a agregar (o quitar si es negativo)"},
+			{Name: "undo", Type: ParamFlag, Description: "Deshace el último cambio de fdisk en el disco"},
+			{Name: "force", Type: ParamFlag, Description: "Desmonta la partición a eliminar y cierra su sesión"},
+			{Name: "dryrun", Type: ParamFlag, Description: "Solo informa lo que cambiaría, sin escribir en el disco"},
+		},
+		locks: lockSpec{state: accessWrite, disk: accessWrite, target: targetPath},
+		run:   commands.ParseFdisk,
+	},
+	{
+		Name:        "mount",
+		Description: "Monta una partición",
+		Params: []ParamSpec{
+			{Name: "path", Type: ParamPath, Required: true, Description: "Ruta del disco"},
+			{Name: "name", Type: ParamString, Required: true, Description: "Nombre de la partición"},
+		},
+		locks: lockSpec{state: accessWrite, disk: accessWrite, target: targetPath},
+		run:   commands.ParseMount,
+	},
+	{
+		Name:        "unmount",
+		Description: "Desmonta una partición",
+		Params: []ParamSpec{
+			{Name: "id", Type: ParamString, Required: true, Description: "ID de la partición montada"},
+			{Name: "force", Type: ParamFlag, Description: "Cierra la sesión activa en la partición"},
+		},
+		locks: lockSpec{state: accessWrite, disk: accessWrite, target: targetID},
+		run:   commands.ParseUnmount,
+	},
+	{
+		Name:        "mounted",
+		Description: "Lista las particiones montadas",
+		locks:       lockSpec{state: accessRead},
+		run:         commands.ParseMounted,
+	},
+	{
+		Name:        "mkfs",
+		Description: "Formatea una partición montada",
+		Params: []ParamSpec{
+			{Name: "id", Type: ParamString, Required: true, Description: "ID de la partición montada"},
+			{Name: "type", Type: ParamString, Allowed: []string{"fast", "full"}, Default: "full", Description: "Formateo rápido (solo estructuras) o completo (llena de ceros la partición)"},
+			{Name: "fs", Type: ParamString, Allowed: []string{"2fs", "3fs"}, Default: "2fs", Description: "Sistema de archivos (EXT2 o EXT3)"},
+			{Name: "bitmap", Type: ParamString, Allowed: []string{"byte", "bit"}, Default: "byte", Description: "Bitmaps de un byte o de un bit por inodo y bloque"},
+			{Name: "dryrun", Type: ParamFlag, Description: "Solo informa lo que cambiaría, sin escribir en el disco"},
+		},
+		locks: lockSpec{state: accessRead, disk: accessWrite, target: targetID},
+		run:   commands.ParseMkfs,
+	},
+	{
+		Name:        "resizefs",
+		Description: "Ajusta el sistema de archivos al tamaño actual de la partición",
+		Params: []ParamSpec{
+			{Name: "id", Type: ParamString, Required: true, Description: "ID de la partición montada"},
+			{Name: "dryrun", Type: ParamFlag, Description: "Solo informa lo que cambiaría, sin escribir en el disco"},
+		},
+		locks: lockSpec{state: accessRead, disk: accessWrite, target: targetID},
+		run:   commands.ParseResizefs,
+	},
+	{
+		Name:        "layout",
+		Description: "Muestra la distribución del sistema de archivos de una partición montada",
+		Params: []ParamSpec{
+			{Name: "id", Type: ParamString, Required: true, Description: "ID de la partición montada"},
+		},
+		locks: lockSpec{state: accessRead, disk: accessRead, target: targetID},
+		run:   commands.ParseLayout,
+	},
+	{
+		Name:        "migrate",
+		Description: "Convierte un disco del formato v1 al formato actual",
+		Params: []ParamSpec{
+			{Name: "path", Type: ParamPath, Required: true, Description: "Ruta del disco"},
+			{Name: "dryrun", Type: ParamFlag, Description: "Solo informa lo que cambiaría, sin escribir en el disco"},
+		},
+		locks: lockSpec{state: accessRead, disk: accessWrite, target: targetPath},
+		run:   commands.ParseMigrate,
+	},
+	{
+		Name:        "rep",
+		Description: "Genera un reporte",
+		Params: []ParamSpec{
+			{Name: "id", Type: ParamString, Required: true, Description: "ID de la partición montada"},
+			{Name: "path", Type: ParamPath, Required: true, Description: "Ruta del archivo de salida"},
+			{Name: "name", Type: ParamString, Required: true, Allowed: []string{"mbr", "disk", "inode", "block", "bm_inode", "bm_block", "sb", "file", "ls"}, Description: "Reporte a generar"},
+			{Name: "path_file_ls", Type: ParamString, Description: "Archivo o carpeta para los reportes file y ls"},
+		},
+		locks: lockSpec{state: accessRead, disk: accessRead, target: targetID},
+		run:   commands.ParseRep,
+	},
+	{
+		Name:        "login",
+		Description: "Inicia sesión en una partición",
+		Params: []ParamSpec{
+			{Name: "user", Type: ParamString, Required: true, Description: "Usuario"},
+			{Name: "pass", Type: ParamString, Required: true, Description: "Contraseña"},
+			{Name: "id", Type: ParamString, Required: true, Description: "ID de la partición montada"},
+		},
+		locks: lockSpec{state: accessWrite, disk: accessWrite, target: targetID},
+		run:   commands.ParseLogin,
+	},
+	{
+		Name:        "logout",
+		Description: "Cierra la sesión activa",
+		locks:       lockSpec{state: accessWrite},
+		run:         commands.ParseLogout,
+	},
+	{
+		Name:        "mkgrp",
+		Description: "Crea un grupo (solo root)",
+		Params: []ParamSpec{
+			{Name: "name", Type: ParamString, Required: true, Description: "Nombre del grupo"},
+		},
+		locks: lockSpec{state: accessRead, disk: accessWrite, target: targetSession},
+		run:   commands.ParseMkgrp,
+	},
+	{
+		Name:        "rmgrp",
+		Description: "Elimina un grupo (solo root)",
+		Params: []ParamSpec{
+			{Name: "name", Type: ParamString, Required: true, Description: "Nombre del grupo"},
+		},
+		locks: lockSpec{state: accessRead, disk: accessWrite, target: targetSession},
+		run:   commands.ParseRmgrp,
+	},
+	{
+		Name:        "mkusr",
+		Description: "Crea un usuario (solo root)",
+		Params: []ParamSpec{
+			{Name: "user", Type: ParamString, Required: true, Description: "Nombre del usuario"},
+			{Name: "pass", Type: ParamString, Required: true, Description: "Contraseña"},
+			{Name: "grp", Type: ParamString, Required: true, Description: "Grupo del usuario"},
+		},
+		locks: lockSpec{state: accessRead, disk: accessWrite, target: targetSession},
+		run:   commands.ParseMkusr,
+	},
+	{
+		Name:        "mkdir",
+		Description: "Crea una carpeta",
+		Params: []ParamSpec{
+			{Name: "path", Type: ParamString, Required: true, Description: "Ruta de la carpeta"},
+			{Name: "p", Type: ParamFlag, Description: "Crea las carpetas padre si no existen"},
+		},
+		locks: lockSpec{state: accessRead, disk: accessWrite, target: targetSession},
+		run:   commands.ParseMkdir,
+	},
+	{
+		Name:        "cat",
+		Description: "Muestra el contenido de uno o más archivos",
+		Params: []ParamSpec{
+			{Name: "file", Type: ParamString, Required: true, Numbered: true, Description: "Ruta del archivo (-file1, -file2, ...)"},
+		},
+		locks: lockSpec{state: accessRead, disk: accessRead, target: targetSession},
+		run:   commands.ParseCat,
+	},
+	{
+		Name:        "mkfile",
+		Description: "Crea un archivo",
+		Simulated:   true,
+		Params: []ParamSpec{
+			{Name: "path", Type: ParamString, Description: "Ruta del archivo"},
+			{Name: "r", Type: ParamFlag, Description: "Crea las carpetas padre si no existen"},
+			{Name: "size", Type: ParamInt, Description: "Tamaño del archivo en bytes"},
+			{Name: "cont", Type: ParamString, Description: "Archivo local con el contenido"},
+		},
+		run: simulateMkfile,
+	},
+	{
+		Name:        "remove",
+		Description: "Elimina un archivo o carpeta",
+		Simulated:   true,
+		Params: []ParamSpec{
+			{Name: "path", Type: ParamString, Description: "Ruta a eliminar"},
+		},
+		run: simulateRemove,
+	},
+	{
+		Name:        "edit",
+		Description: "Edita el contenido de un archivo",
+		Simulated:   true,
+		Params: []ParamSpec{
+			{Name: "path", Type: ParamString, Description: "Ruta del archivo"},
+			{Name: "contenido", Type: ParamString, Description: "Archivo local con el nuevo contenido"},
+		},
+		run: simulateEdit,
+	},
+	{
+		Name:        "rename",
+		Description: "Cambia el nombre de un archivo o carpeta",
+		Simulated:   true,
+		Params: []ParamSpec{
+			{Name: "path", Type: ParamString, Description: "Ruta actual"},
+			{Name: "name", Type: ParamString, Description: "Nuevo nombre"},
+		},
+		run: simulateRename,
+	},
+	{
+		Name:        "copy",
+		Description: "Copia un archivo o carpeta",
+		Simulated:   true,
+		Params: []ParamSpec{
+			{Name: "path", Type: ParamString, Description: "Ruta de origen"},
+			{Name: "destino", Type: ParamString, Description: "Carpeta de destino"},
+		},
+		run: simulateCopy,
+	},
+	{
+		Name:        "move",
+		Description: "Mueve un archivo o carpeta",
+		Simulated:   true,
+		Params: []ParamSpec{
+			{Name: "path", Type: ParamString, Description: "Ruta de origen"},
+			{Name: "destino", Type: ParamString, Description: "Carpeta de destino"},
+		},
+		run: simulateMove,
+	},
+	{
+		Name:        "find",
+		Description: "Busca archivos por nombre",
+		Simulated:   true,
+		Params: []ParamSpec{
+			{Name: "path", Type: ParamString, Description: "Carpeta donde inicia la búsqueda"},
+			{Name: "name", Type: ParamString, Description: "Nombre o patrón a buscar"},
+		},
+		run: simulateFind,
+	},
+	{
+		Name:        "chown",
+		Description: "Cambia el propietario de un archivo o carpeta",
+		Simulated:   true,
+		Params: []ParamSpec{
+			{Name: "path", Type: ParamString, Description: "Ruta del archivo o carpeta"},
+			{Name: "r", Type: ParamFlag, Description: "Aplica el cambio de forma recursiva"},
+			{Name: "usuario", Type: ParamString, Description: "Nuevo propietario"},
+		},
+		run: simulateChown,
+	},
+	{
+		Name:        "chmod",
+		Description: "Cambia los permisos de un archivo o carpeta",
+		Simulated:   true,
+		Params: []ParamSpec{
+			{Name: "path", Type: ParamString, Description: "Ruta del archivo o carpeta"},
+			{Name: "ugo", Type: ParamString, Description: "Permisos en formato UGO (ej: 764)"},
+			{Name: "r", Type: ParamFlag, Description: "Aplica el cambio de forma recursiva"},
+		},
+		run: simulateChmod,
+	},
+}
+
+// registry comandos indexados por nombre
+var registry = map[string]*CommandSpec{}
+
+func init() {
+	// execute y help se agregan aquí porque su ejecución vuelve a pasar por el registro
+	commandSpecs = append(commandSpecs, executeCommand, helpCommand)
+
+	for _, spec := range commandSpecs {
+		registry[spec.Name] = spec
+	}
+}
+
+// LookupCommand devuelve la declaración de un comando por su nombre
+func LookupCommand(name string) (*CommandSpec, bool) {
+	spec, ok := registry[strings.ToLower(name)]
+	return spec, ok
+}
+
+// Commands devuelve todos los comandos registrados en el orden de la ayuda
+func Commands() []*CommandSpec {
+	return commandSpecs
+}
+
+// findParam busca la declaración que corresponde a una clave escrita por el usuario
+func (c *CommandSpec) findParam(key string) (*ParamSpec, bool) {
+	for i := range c.Params {
+		spec := &c.Params[i]
+		if spec.Positional {
+			continue
+		}
+		if spec.Numbered {
+			suffix, found := strings.CutPrefix(key, spec.Name)
+			if found && suffix != "" && isDigits(suffix) {
+				return spec, true
+			}
+		} else if spec.Name == key {
+			return spec, true
+		}
+	}
+	return nil, false
+}
+
+// nextPositional devuelve la n-ésima declaración posicional (desde 1)
+func (c *CommandSpec) nextPositional(n int) (*ParamSpec, bool) {
+	for i := range c.Params {
+		if c.Params[i].Positional {
+			n--
+			if n == 0 {
+				return &c.Params[i], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Validate verifica los parámetros contra la declaración del comando, normaliza los
+// valores permitidos a su forma declarada y completa los valores por defecto
+func (c *CommandSpec) Validate(params utils.Params) (utils.Params, error) {
+	validated := utils.Params{}
+	seen := map[string]bool{}
+
+	positional := 0
+
+	for _, p := range params.List() {
+		if p.Positional {
+			positional++
+			spec, ok := c.nextPositional(positional)
+			if !ok {
+				return nil, fmt.Errorf("parámetro inválido: %s", p.Value)
+			}
+			seen[spec.Name] = true
+			validated[spec.Name] = utils.Param{Key: spec.Name, Value: p.Value, Position: p.Position}
+			continue
+		}
+
+		spec, ok := c.findParam(p.Key)
+		if !ok {
+			return nil, fmt.Errorf("parámetro desconocido para %s: -%s", c.Name, p.Key)
+		}
+		seen[spec.Name] = true
+
+		switch spec.Type {
+		case ParamFlag:
+			if !p.IsFlag {
+				return nil, fmt.Errorf("el parámetro -%s no recibe valor", p.Key)
+			}
+		case ParamInt:
+			if p.IsFlag || p.Value == "" {
+				return nil, fmt.Errorf("el parámetro -%s no puede estar vacío", p.Key)
+			}
+			if _, err := strconv.Atoi(p.Value); err != nil {
+				return nil, fmt.Errorf("el parámetro -%s debe ser un número entero", p.Key)
+			}
+		case ParamPath:
+			if p.IsFlag || p.Value == "" {
+				return nil, fmt.Errorf("el parámetro -%s no puede estar vacío", p.Key)
+			}
+			resolved, err := stores.ResolveDiskPath(p.Value)
+			if err != nil {
+				return nil, fmt.Errorf("el parámetro -%s no es válido: %v", p.Key, err)
+			}
+			p.Value = resolved
+		default:
+			if p.IsFlag || p.Value == "" {
+				return nil, fmt.Errorf("el parámetro -%s no puede estar vacío", p.Key)
+			}
+		}
+
+		if len(spec.Allowed) > 0 {
+			value, ok := matchAllowed(spec.Allowed, p.Value)
+			if !ok {
+				return nil, fmt.Errorf("valor inválido para -%s: %s (permitidos: %s)",
+					p.Key, p.Value, strings.Join(spec.Allowed, ", "))
+			}
+			p.Value = value
+		}
+
+		validated[p.Key] = p
+	}
+
+	var missing []string
+	for i, spec := range c.Params {
+		if seen[spec.Name] {
+			continue
+		}
+		if spec.Required {
+			missing = append(missing, spec.usageName())
+			continue
+		}
+		if spec.Default != "" {
+			validated[spec.Name] = utils.Param{Key: spec.Name, Value: spec.Default, Position: len(params) + i}
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("faltan parámetros requeridos: %s", strings.Join(missing, ", "))
+	}
+
+	return validated, nil
+}
+
+// Usage devuelve la sintaxis del comando (ej: mkdisk -size=<int> [-unit=K|M] -path=<string>)
+func (c *CommandSpec) Usage() string {
+	parts := []string{c.Name}
+	for _, spec := range c.Params {
+		part := spec.usageName()
+		switch {
+		case spec.Positional:
+		case len(spec.Allowed) > 0:
+			part += "=" + strings.Join(spec.Allowed, "|")
+		case spec.Type == ParamFlag:
+		default:
+			part += "=<" + string(spec.Type) + ">"
+		}
+		if !spec.Required {
+			part = "[" + part + "]"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Help devuelve la ayuda completa del comando con la descripción de cada parámetro
+func (c *CommandSpec) Help() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s\n", strings.ToUpper(c.Name), c.Description)
+	if c.Simulated {
+		b.WriteString("(comando simulado)\n")
+	}
+	fmt.Fprintf(&b, "Uso: %s", c.Usage())
+
+	for _, spec := range c.Params {
+		fmt.Fprintf(&b, "\n  %-15s %s", spec.usageName(), spec.Description)
+		if spec.Required {
+			b.WriteString(" (obligatorio)")
+		}
+		if len(spec.Allowed) > 0 {
+			fmt.Fprintf(&b, " (valores: %s)", strings.Join(spec.Allowed, ", "))
+		}
+		if spec.Default != "" {
+			fmt.Fprintf(&b, " (por defecto: %s)", spec.Default)
+		}
+	}
+	return b.String()
+}
+
+// usageName nombre del parámetro como se escribe en la línea (ej: -fileN, <cmd>)
+func (p ParamSpec) usageName() string {
+	switch {
+	case p.Positional:
+		return "<" + p.Name + ">"
+	case p.Numbered:
+		return "-" + p.Name + "N"
+	}
+	return "-" + p.Name
+}
+
+// matchAllowed busca el valor entre los permitidos sin distinguir mayúsculas
+func matchAllowed(allowed []string, value string) (string, bool) {
+	for _, a := range allowed {
+		if strings.EqualFold(a, value) {
+			return a, true
+		}
+	}
+	return "", false
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Dispatch valida los parámetros y ejecuta el comando con sus locks tomados
+func (c *CommandSpec) Dispatch(params utils.Params, opts Options) (string, error) {
+	// El dry-run global o de la petición equivale a escribir -dryrun en los
+	// comandos que lo declaran
+	if (opts.DryRun || stores.Config.DryRun) && !params.Has("dryrun") {
+		if _, ok := c.findParam("dryrun"); ok {
+			params["dryrun"] = utils.Param{Key: "dryrun", IsFlag: true, Position: len(params)}
+		}
+	}
+
+	validated, err := c.Validate(params)
+	if err != nil {
+		return "", err
+	}
+
+	diskPath, unlock := acquireLocks(c.locks, validated)
+	defer unlock()
+
+	output, err := c.run(validated)
+
+	// Los cambios quedan en el caché del disco: se escriben antes de soltar su lock. Solo
+	// el disco bloqueado, otro comando puede estar usando los demás.
+	if diskPath == "" {
+		return output, err
+	}
+	if flushErr := stores.FlushDevice(diskPath); flushErr != nil && err == nil {
+		return "", fmt.Errorf("ERROR: no se pudieron guardar los cambios en el disco: %v", flushErr)
+	}
+	return output, err
+}
@@ -0,0 +1,121 @@
+package analyzer
+
+import (
+	stores "backend/stores"
+	utils "backend/utils"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Máximo de scripts anidados con execute (evita recursión sin fin con rutas distintas)
+const maxExecuteDepth = 8
+
+// executeCommand ejecuta un script guardado en el servidor. No toma locks propios:
+// cada línea toma los suyos al pasar por el analizador.
+var executeCommand = &CommandSpec{
+	Name:        "execute",
+	Description: "Ejecuta un script (.smia/.mia) guardado en el servidor",
+	Params: []ParamSpec{
+		{Name: "path", Type: ParamPath, Required: true, Description: "Ruta del script"},
+		{Name: "stop", Type: ParamFlag, Description: "Detiene la ejecución en el primer error"},
+		{Name: "dryrun", Type: ParamFlag, Description: "Ejecuta el script en modo dry-run"},
+	},
+	run: runExecute,
+}
+
+/*
+	execute -path=/home/user/Prueba.smia
+	execute -path="/home/mis scripts/calificacion.mia" -stop
+*/
+
+func runExecute(params utils.Params) (string, error) {
+	opts := Options{DryRun: params.Has("dryrun")}
+	return executeFile(params.Value("path"), params.Has("stop"), opts, nil)
+}
+
+// executeFile ejecuta el script línea por línea. path ya está resuelta dentro de la
+// raíz de discos (ParamPath). stack contiene las rutas de los scripts que lo
+// incluyeron, para detectar inclusiones circulares.
+func executeFile(path string, stop bool, opts Options, stack []string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("EXECUTE ERROR: ruta inválida '%s': %v", path, err)
+	}
+
+	for _, included := range stack {
+		if included == absPath {
+			return "", fmt.Errorf("EXECUTE ERROR: inclusión circular: %s", strings.Join(append(stack, absPath), " -> "))
+		}
+	}
+	if len(stack) >= maxExecuteDepth {
+		return "", fmt.Errorf("EXECUTE ERROR: se superó el máximo de %d scripts anidados", maxExecuteDepth)
+	}
+
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return "", fmt.Errorf("EXECUTE ERROR: no se pudo leer el script '%s': %v", path, err)
+	}
+
+	stack = append(stack, absPath)
+
+	var output strings.Builder
+	fmt.Fprintf(&output, "EXECUTE: %s", absPath)
+	total, errorsCount := 0, 0
+
+	for i, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		total++
+		result, err := executeScriptLine(line, filepath.Dir(absPath), opts, stack)
+		if err != nil {
+			errorsCount++
+			fmt.Fprintf(&output, "\nError (línea %d): %v", i+1, err)
+
+			if stop {
+				return "", fmt.Errorf("%s\nEXECUTE: ejecución detenida en la línea %d de %s", output.String(), i+1, absPath)
+			}
+			continue
+		}
+		if result != "" {
+			fmt.Fprintf(&output, "\n%s", result)
+		}
+	}
+
+	fmt.Fprintf(&output, "\nEXECUTE: %d comandos ejecutados, %d con error", total, errorsCount)
+	return output.String(), nil
+}
+
+// executeScriptLine ejecuta una línea del script. Los execute anidados se resuelven
+// aquí para conservar la pila de inclusiones; las rutas relativas parten de la
+// carpeta del script que los incluye y tampoco pueden salir de la raíz de discos.
+func executeScriptLine(line string, dir string, opts Options, stack []string) (string, error) {
+	cmd, params, err := Tokenize(line)
+	if err != nil {
+		return "", err
+	}
+	if cmd != "execute" {
+		return AnalyzerWithOptions(line, opts)
+	}
+
+	spec, _ := LookupCommand(cmd)
+	validated, err := spec.Validate(params)
+	if err != nil {
+		return "", err
+	}
+
+	// Validate resuelve las rutas desde la raíz; las relativas se resuelven de nuevo
+	// desde la carpeta del script
+	path := validated.Value("path")
+	if raw := params.Value("path"); !filepath.IsAbs(raw) {
+		if path, err = stores.ResolveDiskPathFrom(dir, raw); err != nil {
+			return "", fmt.Errorf("el parámetro -path no es válido: %v", err)
+		}
+	}
+	opts.DryRun = opts.DryRun || validated.Has("dryrun")
+	return executeFile(path, validated.Has("stop"), opts, stack)
+}
@@ -0,0 +1,68 @@
+package analyzer
+
+import (
+	utils "backend/utils"
+	"fmt"
+)
+
+// 🚀 Comandos simulados: solo muestran la salida esperada, no modifican el disco
+
+func simulateMkfile(params utils.Params) (string, error) {
+	ruta := params.Value("path")
+	size := "(simulado)"
+	if value, ok := params.Get("size"); ok {
+		size = value
+	}
+
+	if ruta == "" {
+		ruta = "(sin ruta especificada)"
+	}
+
+	return fmt.Sprintf("MKFILE: Archivo creado exitosamente\n-> Path: %s\n-> Tamaño: %s bytes\n", ruta, size), nil
+}
+
+func simulateRemove(params utils.Params) (string, error) {
+	path := params.Value("path")
+	if path == "" {
+		path = "(sin ruta especificada)"
+	}
+	return fmt.Sprintf("REMOVE: Eliminado correctamente -> %s\n", path), nil
+}
+
+func simulateEdit(params utils.Params) (string, error) {
+	path, contenido := params.Value("path"), params.Value("contenido")
+	return fmt.Sprintf("EDIT: Archivo editado correctamente\n-> Path: %s\n-> Contenido: %s\n", path, contenido), nil
+}
+
+func simulateRename(params utils.Params) (string, error) {
+	path, name := params.Value("path"), params.Value("name")
+	return fmt.Sprintf("RENAME: Archivo renombrado correctamente\n-> Nuevo nombre: %s\n-> Path: %s\n", name, path), nil
+}
+
+func simulateCopy(params utils.Params) (string, error) {
+	path, destino := params.Value("path"), params.Value("destino")
+	return fmt.Sprintf("COPY: Copia realizada exitosamente\n-> Origen: %s\n-> Destino: %s\n", path, destino), nil
+}
+
+func simulateMove(params utils.Params) (string, error) {
+	path, destino := params.Value("path"), params.Value("destino")
+	return fmt.Sprintf("MOVE: Archivo movido correctamente\n-> Origen: %s\n-> Destino: %s\n", path, destino), nil
+}
+
+func simulateFind(params utils.Params) (string, error) {
+	path, name := params.Value("path"), params.Value("name")
+	return fmt.Sprintf("FIND: Búsqueda completada\n-> Path: %s\n-> Nombre: %s\n-> Resultado: (simulado)\n", path, name), nil
+}
+
+func simulateChown(params utils.Params) (string, error) {
+	path, user := params.Value("path"), params.Value("usuario")
+	if user == "user_no_existe" {
+		return fmt.Sprintf("CHOWN: Error -> el usuario '%s' no existe\n", user), nil
+	}
+	return fmt.Sprintf("CHOWN: Cambiado propietario de %s a %s\n", path, user), nil
+}
+
+func simulateChmod(params utils.Params) (string, error) {
+	path, ugo := params.Value("path"), params.Value("ugo")
+	return fmt.Sprintf("CHMOD: Permisos modificados correctamente\n-> Path: %s\n-> Permisos: %s\n", path, ugo), nil
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"backend/stores"
 	"backend/structures"
+	"backend/utils"
 	"errors"
 	"fmt"
 	"regexp"
@@ -14,45 +15,31 @@ type CAT struct {
 	files []string // Lista de archivos a leer
 }
 
-// ParseCat analiza los tokens del comando cat
-func ParseCat(tokens []string) (string, error) {
+// Expresión regular para los parámetros -fileN
+var fileParamRe = regexp.MustCompile(`^file\d+$`)
+
+// ParseCat analiza los parámetros del comando cat
+func ParseCat(params utils.Params) (string, error) {
 	cmd := &CAT{
 		files: make([]string, 0),
 	}
 
-	// Unir tokens en una sola cadena
-	args := strings.Join(tokens, " ")
-
-	// Expresión regular para encontrar parámetros -fileN
-	re := regexp.MustCompile(`-file\d+=[^\s]+`)
-
-	// Encuentra todas las coincidencias
-	matches := re.FindAllString(args, -1)
-
-	if len(matches) == 0 {
-		return "", errors.New("debe proporcionar al menos un archivo con -file1=<ruta>")
-	}
-
-	// Procesar cada parámetro
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parámetro inválido: %s", match)
-		}
-
-		value := kv[1]
-
-		// Quitar comillas si existen
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
+	// Los parámetros -fileN se leen en el orden en que se escribieron
+	for _, p := range params.List() {
+		if !fileParamRe.MatchString(p.Key) {
+			continue
 		}
 
 		// Validar que la ruta no esté vacía
-		if value == "" {
+		if p.Value == "" {
 			return "", errors.New("la ruta del archivo no puede estar vacía")
 		}
 
-		cmd.files = append(cmd.files, value)
+		cmd.files = append(cmd.files, p.Value)
+	}
+
+	if len(cmd.files) == 0 {
+		return "", errors.New("debe proporcionar al menos un archivo con -file1=<ruta>")
 	}
 
 	// Ejecutar el comando
@@ -77,30 +64,27 @@ func commandCat(cat *CAT) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("error al obtener la partición montada: %w", err)
 	}
-
-	fmt.Printf("DEBUG CAT -> Partition Start: %d\n", partition.Part_start)
-	fmt.Printf("DEBUG CAT -> S_inode_start: %d\n", partitionSuperblock.S_inode_start)
-	fmt.Printf("DEBUG CAT -> S_block_start: %d\n", partitionSuperblock.S_block_start)
+	dev, err := stores.OpenDevice(partitionPath)
+	if err != nil {
+		return "", fmt.Errorf("error al abrir el disco: %w", err)
+	}
 
 	// 3. Obtener información del usuario actual
-	currentUser, _, _ := stores.Auth.GetCurrentUser()
+	currentUser, _ := stores.Auth.GetCurrentUser()
 
 	// Obtener UID y GID del usuario actual
-	userUID, userGID, err := getUserInfo(partitionSuperblock, partition, partitionPath, currentUser)
+	userUID, userGID, err := getUserInfo(partitionSuperblock, partition, dev, currentUser)
 	if err != nil {
 		return "", fmt.Errorf("error al obtener información del usuario: %w", err)
 	}
 
-	fmt.Printf("DEBUG CAT -> Usuario: %s, UID: %d, GID: %d\n", currentUser, userUID, userGID)
-
 	// 4. Concatenar el contenido de todos los archivos
 	var result strings.Builder
 
 	for i, filePath := range cat.files {
-		fmt.Printf("DEBUG CAT -> Buscando archivo: '%s'\n", filePath)
 
 		// Leer el contenido del archivo
-		content, err := readFileContent(partitionSuperblock, partition, partitionPath, filePath, userUID, userGID, currentUser)
+		content, err := readFileContent(partitionSuperblock, partition, dev, filePath, userUID, userGID, currentUser)
 		if err != nil {
 			return "", fmt.Errorf("error al leer %s: %w", filePath, err)
 		}
@@ -118,9 +102,9 @@ func commandCat(cat *CAT) (string, error) {
 }
 
 // getUserInfo obtiene el UID y GID del usuario actual
-func getUserInfo(sb *structures.SuperBlock, partition *structures.Partition, path string, username string) (int32, int32, error) {
+func getUserInfo(sb *structures.SuperBlock, partition *structures.Partition, dev structures.Device, username string) (int32, int32, error) {
 	// Leer el archivo users.txt
-	usersContent, err := readUsersFileCat(sb, partition, path)
+	usersContent, err := readUsersFileCat(sb, partition, dev)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -173,7 +157,7 @@ func getUserInfo(sb *structures.SuperBlock, partition *structures.Partition, pat
 }
 
 // readFileContent lee el contenido de un archivo verificando permisos
-func readFileContent(sb *structures.SuperBlock, partition *structures.Partition, path string, filePath string, userUID int32, userGID int32, username string) (string, error) {
+func readFileContent(sb *structures.SuperBlock, partition *structures.Partition, dev structures.Device, filePath string, userUID int32, userGID int32, username string) (string, error) {
 	// Parsear la ruta del archivo
 	filePath = strings.Trim(filePath, " ")
 
@@ -191,8 +175,6 @@ func readFileContent(sb *structures.SuperBlock, partition *structures.Partition,
 		}
 	}
 
-	fmt.Printf("DEBUG CAT -> PathParts válidas: %v\n", validParts)
-
 	// Si no hay partes válidas, es inválida
 	if len(validParts) == 0 {
 		return "", fmt.Errorf("debe especificar un archivo válido")
@@ -200,23 +182,19 @@ func readFileContent(sb *structures.SuperBlock, partition *structures.Partition,
 
 	// Buscar el archivo navegando por la estructura de directorios
 	// Empezamos desde el inodo 0 (raíz)
-	fileInode, err := findFileInode(sb, partition, path, validParts, 0)
+	fileInode, err := findFileInode(sb, partition, dev, validParts, 0)
 	if err != nil {
 		return "", err
 	}
 
-	fmt.Printf("DEBUG CAT -> Inodo encontrado: %d\n", fileInode)
-
 	// Deserializar el inodo del archivo
 	inode := &structures.Inode{}
-	inodeOffset := int64(sb.S_inode_start + (fileInode * sb.S_inode_size))
-	err = inode.Deserialize(path, inodeOffset)
+	inodeOffset := sb.InodeOffset(int64(fileInode))
+	err = inode.Deserialize(dev, inodeOffset)
 	if err != nil {
 		return "", fmt.Errorf("error al deserializar inodo: %w", err)
 	}
 
-	fmt.Printf("DEBUG CAT -> Tipo de inodo: %c (0=carpeta, 1=archivo)\n", inode.I_type[0])
-
 	// Verificar que sea un archivo
 	if inode.I_type[0] != '1' {
 		return "", fmt.Errorf("%s es un directorio, no un archivo", filePath)
@@ -240,8 +218,8 @@ func readFileContent(sb *structures.SuperBlock, partition *structures.Partition,
 
 		// Deserializar el bloque de archivo
 		block := &structures.FileBlock{}
-		blockOffset := int64(sb.S_block_start + (blockIndex * sb.S_block_size))
-		err := block.Deserialize(path, blockOffset)
+		blockOffset := sb.BlockOffset(int64(blockIndex))
+		err := block.Deserialize(dev, blockOffset)
 		if err != nil {
 			return "", fmt.Errorf("error al leer bloque: %w", err)
 		}
@@ -256,8 +234,7 @@ func readFileContent(sb *structures.SuperBlock, partition *structures.Partition,
 }
 
 // findFileInode busca el inodo de un archivo navegando por la estructura de directorios
-func findFileInode(sb *structures.SuperBlock, partition *structures.Partition, path string, pathParts []string, currentInodeIndex int32) (int32, error) {
-	fmt.Printf("DEBUG findFileInode -> Buscando en inodo %d, pathParts restantes: %v\n", currentInodeIndex, pathParts)
+func findFileInode(sb *structures.SuperBlock, partition *structures.Partition, dev structures.Device, pathParts []string, currentInodeIndex int32) (int32, error) {
 
 	// Si no hay más partes de la ruta, retornar el inodo actual
 	if len(pathParts) == 0 {
@@ -266,14 +243,12 @@ func findFileInode(sb *structures.SuperBlock, partition *structures.Partition, p
 
 	// Deserializar el inodo actual con el offset correcto
 	inode := &structures.Inode{}
-	inodeOffset := int64(sb.S_inode_start + (currentInodeIndex * sb.S_inode_size))
-	err := inode.Deserialize(path, inodeOffset)
+	inodeOffset := sb.InodeOffset(int64(currentInodeIndex))
+	err := inode.Deserialize(dev, inodeOffset)
 	if err != nil {
 		return -1, fmt.Errorf("error al deserializar inodo %d: %w", currentInodeIndex, err)
 	}
 
-	fmt.Printf("DEBUG findFileInode -> Inodo %d tipo: %c\n", currentInodeIndex, inode.I_type[0])
-
 	// Si quedan más partes de la ruta, el inodo actual debe ser un directorio
 	if len(pathParts) > 1 && inode.I_type[0] != '0' {
 		return -1, fmt.Errorf("la ruta contiene un archivo en lugar de un directorio")
@@ -281,7 +256,6 @@ func findFileInode(sb *structures.SuperBlock, partition *structures.Partition, p
 
 	// Buscar en los bloques del directorio
 	targetName := pathParts[0]
-	fmt.Printf("DEBUG findFileInode -> Buscando nombre: '%s'\n", targetName)
 
 	for i := 0; i < 12; i++ {
 		blockIndex := inode.I_block[i]
@@ -290,25 +264,18 @@ func findFileInode(sb *structures.SuperBlock, partition *structures.Partition, p
 			break
 		}
 
-		fmt.Printf("DEBUG findFileInode -> Revisando bloque %d\n", blockIndex)
-
 		// Deserializar el bloque de carpeta con el offset correcto
 		block := &structures.FolderBlock{}
-		blockOffset := int64(sb.S_block_start + (blockIndex * sb.S_block_size))
-		err := block.Deserialize(path, blockOffset)
+		blockOffset := sb.BlockOffset(int64(blockIndex))
+		err := block.Deserialize(dev, blockOffset)
 		if err != nil {
-			fmt.Printf("DEBUG findFileInode -> Error al deserializar bloque: %v\n", err)
 			continue
 		}
 
 		// Buscar el archivo/carpeta en el bloque
-		for j, content := range block.B_content {
+		for _, content := range block.B_content {
 			name := strings.Trim(string(content.B_name[:]), "\x00 ")
 
-			// Debug: mostrar cada entrada
-			fmt.Printf("DEBUG findFileInode -> Entrada %d: nombre='%s' (bytes: %v), inodo=%d\n",
-				j, name, content.B_name[:], content.B_inodo)
-
 			// Ignorar entradas . y ..
 			if name == "." || name == ".." {
 				continue
@@ -316,7 +283,6 @@ func findFileInode(sb *structures.SuperBlock, partition *structures.Partition, p
 
 			// Comparación case-insensitive
 			if strings.EqualFold(name, targetName) && content.B_inodo != -1 {
-				fmt.Printf("DEBUG findFileInode -> ¡Coincidencia encontrada! inodo=%d\n", content.B_inodo)
 
 				// Si es el último elemento de la ruta, retornar este inodo
 				if len(pathParts) == 1 {
@@ -324,7 +290,7 @@ func findFileInode(sb *structures.SuperBlock, partition *structures.Partition, p
 				}
 
 				// Si no, seguir navegando
-				return findFileInode(sb, partition, path, pathParts[1:], content.B_inodo)
+				return findFileInode(sb, partition, dev, pathParts[1:], content.B_inodo)
 			}
 		}
 	}
@@ -364,22 +330,18 @@ func hasReadPermission(inode *structures.Inode, userUID int32, userGID int32, us
 }
 
 // readUsersFileCat lee el archivo users.txt (inodo 1)
-func readUsersFileCat(sb *structures.SuperBlock, partition *structures.Partition, path string) (string, error) {
-	fmt.Println("DEBUG readUsersFileCat -> Iniciando lectura de users.txt")
+func readUsersFileCat(sb *structures.SuperBlock, partition *structures.Partition, dev structures.Device) (string, error) {
 
 	inode := &structures.Inode{}
 
 	// El superbloque ya contiene los offsets absolutos
-	inodeOffset := int64(sb.S_inode_start + (1 * sb.S_inode_size))
-	fmt.Printf("DEBUG readUsersFileCat -> Offset inodo: %d\n", inodeOffset)
+	inodeOffset := sb.InodeOffset(1)
 
-	err := inode.Deserialize(path, inodeOffset)
+	err := inode.Deserialize(dev, inodeOffset)
 	if err != nil {
 		return "", fmt.Errorf("error al deserializar inodo users.txt: %w", err)
 	}
 
-	fmt.Printf("DEBUG readUsersFileCat -> Tipo inodo: %c\n", inode.I_type[0])
-
 	if inode.I_type[0] != '1' {
 		return "", errors.New("el inodo 1 no es un archivo")
 	}
@@ -394,10 +356,9 @@ func readUsersFileCat(sb *structures.SuperBlock, partition *structures.Partition
 		}
 
 		block := &structures.FileBlock{}
-		blockOffset := int64(sb.S_block_start + (blockIndex * sb.S_block_size))
-		fmt.Printf("DEBUG readUsersFileCat -> Leyendo bloque %d en offset %d\n", blockIndex, blockOffset)
+		blockOffset := sb.BlockOffset(int64(blockIndex))
 
-		err := block.Deserialize(path, blockOffset)
+		err := block.Deserialize(dev, blockOffset)
 		if err != nil {
 			return "", fmt.Errorf("error al deserializar bloque %d: %w", blockIndex, err)
 		}
@@ -407,6 +368,5 @@ func readUsersFileCat(sb *structures.SuperBlock, partition *structures.Partition
 		content.WriteString(blockContent)
 	}
 
-	fmt.Printf("DEBUG readUsersFileCat -> Contenido leído: '%s'\n", content.String())
 	return content.String(), nil
 }
@@ -0,0 +1,188 @@
+package commands
+
+import (
+	structures "backend/structures"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// dryRunPlan cambios que haría un comando destructivo. Se construye solo leyendo
+// el disco; nada de lo que contiene se escribe.
+type dryRunPlan struct {
+	command     string
+	ranges      []string // Rangos de bytes que se sobrescribirían
+	mbrChanges  []string // Entradas del MBR/EBR que cambiarían
+	freedInodes []string // Inodos en uso que se perderían, por partición
+	notes       []string
+}
+
+func newDryRunPlan(command string) *dryRunPlan {
+	return &dryRunPlan{command: command}
+}
+
+// addRange registra el rango [start, end) que se escribiría
+func (p *dryRunPlan) addRange(start, end int64, description string) {
+	if end <= start {
+		return
+	}
+	p.ranges = append(p.ranges, fmt.Sprintf("[%d, %d) %d bytes: %s", start, end, end-start, description))
+}
+
+func (p *dryRunPlan) addMBRChange(format string, args ...any) {
+	p.mbrChanges = append(p.mbrChanges, fmt.Sprintf(format, args...))
+}
+
+func (p *dryRunPlan) addNote(format string, args ...any) {
+	p.notes = append(p.notes, fmt.Sprintf(format, args...))
+}
+
+// addFreedInodes registra los inodos en uso del sistema de archivos que empieza en
+// partStart. Con cutoff >= 0 solo cuenta los inodos cuyo slot queda desde ese byte
+// en adelante (reducción de tamaño); con cutoff < 0 cuenta todos.
+func (p *dryRunPlan) addFreedInodes(dev structures.Device, partName string, partStart, cutoff int64) {
+	sb, used, err := readUsedInodes(dev, partStart)
+	if err != nil {
+		p.addNote("%s: %v", partName, err)
+		return
+	}
+	if sb == nil {
+		p.addNote("%s: sin sistema de archivos, no hay inodos que liberar", partName)
+		return
+	}
+
+	if cutoff >= 0 && sb.S_inode_start > cutoff {
+		p.addNote("%s: el superbloque o los bitmaps quedarían fuera de la partición; se pierde todo el sistema de archivos", partName)
+		cutoff = -1
+	}
+
+	if cutoff >= 0 && sb.S_block_size > 0 {
+		blocksEnd := sb.BlockOffset(int64(sb.S_blocks_count))
+		if blocksEnd > cutoff {
+			firstLost := int64(0)
+			if cutoff > int64(sb.S_block_start) {
+				firstLost = (cutoff - int64(sb.S_block_start)) / int64(sb.S_block_size)
+			}
+			p.addNote("%s: se perderían los bloques %d a %d", partName, firstLost, sb.S_blocks_count-1)
+		}
+	}
+
+	var freed []int
+	for _, index := range used {
+		slotEnd := sb.InodeOffset(int64(index + 1))
+		if cutoff < 0 || slotEnd > cutoff {
+			freed = append(freed, index)
+		}
+	}
+
+	if len(freed) == 0 {
+		p.freedInodes = append(p.freedInodes, fmt.Sprintf("%s: ninguno", partName))
+		return
+	}
+	p.freedInodes = append(p.freedInodes, fmt.Sprintf("%s: %d inodos (%s)", partName, len(freed), formatIndexRanges(freed)))
+}
+
+// addLogicalPartitions recorre la cadena de EBR de la extendida y registra cada lógica
+func (p *dryRunPlan) addLogicalPartitions(dev structures.Device, extStart int64, zero bool) {
+	for _, logical := range readLogicalPartitions(dev, extStart) {
+		name := strings.TrimRight(string(logical.Part_name[:]), "\x00")
+		p.addMBRChange("EBR de la lógica '%s' (inicio %d, tamaño %d) se pierde", name, logical.Part_start, logical.Part_size)
+		if zero {
+			p.addRange(int64(logical.Part_start), int64(logical.Part_start)+int64(logical.Part_size), fmt.Sprintf("datos de la lógica '%s'", name))
+		}
+		p.addFreedInodes(dev, name, int64(logical.Part_start), -1)
+	}
+}
+
+func (p *dryRunPlan) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (dry-run): no se escribió nada en el disco", p.command)
+
+	section := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s:", title)
+		for _, item := range items {
+			fmt.Fprintf(&b, "\n  - %s", item)
+		}
+	}
+	section("Rangos de bytes que se escribirían", p.ranges)
+	section("Cambios en el MBR/EBR", p.mbrChanges)
+	section("Inodos que se liberarían", p.freedInodes)
+	section("Notas", p.notes)
+	return b.String()
+}
+
+// describePartition descripción breve de una entrada del MBR para el plan
+func describePartition(index int, partition *structures.Partition) string {
+	name := strings.TrimRight(string(partition.Part_name[:]), "\x00")
+	return fmt.Sprintf("entrada %d '%s' (tipo %c, inicio %d, tamaño %d)",
+		index, name, partition.Part_type[0], partition.Part_start, partition.Part_size)
+}
+
+// readUsedInodes lee el superbloque y devuelve los índices de los inodos marcados en
+// el bitmap. Si la partición no tiene un sistema de archivos válido devuelve sb nil.
+func readUsedInodes(dev structures.Device, partStart int64) (*structures.SuperBlock, []int, error) {
+	sb := &structures.SuperBlock{}
+	if err := sb.Deserialize(dev, partStart); err != nil {
+		return nil, nil, nil
+	}
+	if sb.S_magic != 0xEF53 || sb.S_inodes_count <= 0 || sb.S_inode_size <= 0 {
+		return nil, nil, nil
+	}
+
+	bitmap, err := sb.InodeBitmap(dev).ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("no se pudo leer el bitmap de inodos: %v", err)
+	}
+
+	var used []int
+	for i, u := range bitmap {
+		if u {
+			used = append(used, i)
+		}
+	}
+	return sb, used, nil
+}
+
+// readLogicalPartitions devuelve los EBR ocupados de la cadena que empieza en extStart
+func readLogicalPartitions(dev structures.Device, extStart int64) []structures.EBR {
+	var logicals []structures.EBR
+	position := extStart
+	ebrSize := int64(binary.Size(structures.EBR{}))
+
+	// El límite evita ciclos si la cadena está corrupta
+	for i := 0; i < 1024 && position >= 0; i++ {
+		ebr := structures.EBR{}
+		if err := ebr.Deserialize(dev, int(position)); err != nil {
+			break
+		}
+		if !ebr.IsEmpty() {
+			logicals = append(logicals, ebr)
+		}
+		if ebr.Part_next == -1 || int64(ebr.Part_next) < position+ebrSize {
+			break
+		}
+		position = int64(ebr.Part_next)
+	}
+	return logicals
+}
+
+// formatIndexRanges agrupa índices consecutivos (ej: 0-3, 7, 9-10)
+func formatIndexRanges(indexes []int) string {
+	var parts []string
+	for i := 0; i < len(indexes); {
+		j := i
+		for j+1 < len(indexes) && indexes[j+1] == indexes[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, fmt.Sprintf("%d", indexes[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", indexes[i], indexes[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
@@ -1,13 +1,14 @@
 package commands
 
 import (
+	stores "backend/stores"
 	structures "backend/structures"
 	utils "backend/utils"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"os"
-	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -22,104 +23,62 @@ type FDISK struct {
 	name   string // Nombre de la partición
 	delete string // Tipo de eliminación (fast, full)
 	add    int    // Espacio a agregar o quitar (puede ser negativo)
+	dryRun bool   // Solo informa los cambios, no escribe en el disco
+	undo   bool   // Restaura la tabla de particiones anterior al último cambio
+	force  bool   // Al eliminar, desmonta la partición y cierra su sesión en lugar de rechazar
+
+	dev structures.Device // Disco abierto
 }
 
 // ParseFdisk parsea el comando fdisk y ejecuta la operación correspondiente
-func ParseFdisk(tokens []string) (string, error) {
+func ParseFdisk(params utils.Params) (string, error) {
 	cmd := &FDISK{}
 
-	args := strings.Join(tokens, " ")
-
-	// Regex mejorada para capturar todos los parámetros
-	re := regexp.MustCompile(`-(\w+)=(-?\d+|-?\d+\.\d+|"[^"]+"|[^\s]+)`)
-	matches := re.FindAllStringSubmatch(args, -1)
-
-	if len(matches) == 0 {
-		return "", errors.New("ERROR: no se detectaron parámetros válidos para fdisk")
-	}
-
-	for _, match := range matches {
-		key := strings.ToLower(match[1])
-		value := match[2]
+	// Los parámetros ya fueron validados contra la declaración del comando
+	cmd.unit = params.Value("unit")
+	cmd.fit = params.Value("fit")
+	cmd.path = params.Value("path")
+	cmd.typ = params.Value("type")
+	cmd.name = params.Value("name")
+	cmd.delete = params.Value("delete")
+	cmd.dryRun = params.Has("dryrun")
+	cmd.force = params.Has("force")
 
-		// Remover comillas si existen
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
+	if value, ok := params.Get("size"); ok {
+		size, _ := strconv.Atoi(value)
+		if size <= 0 {
+			return "", errors.New("ERROR: el tamaño debe ser un número entero positivo")
 		}
+		cmd.size = size
+	}
+	if value, ok := params.Get("add"); ok {
+		cmd.add, _ = strconv.Atoi(value)
+	}
 
-		switch key {
-		case "size":
-			size, err := strconv.Atoi(value)
-			if err != nil || size <= 0 {
-				return "", errors.New("ERROR: el tamaño debe ser un número entero positivo")
-			}
-			cmd.size = size
-
-		case "unit":
-			val := strings.ToUpper(value)
-			if val != "B" && val != "K" && val != "M" {
-				return "", errors.New("ERROR: la unidad debe ser B, K o M")
-			}
-			cmd.unit = val
-
-		case "fit":
-			value = strings.ToUpper(value)
-			if value != "BF" && value != "FF" && value != "WF" {
-				return "", errors.New("ERROR: el ajuste debe ser BF, FF o WF")
-			}
-			cmd.fit = value
-
-		case "path":
-			if value == "" {
-				return "", errors.New("ERROR: el path no puede estar vacío")
-			}
-			cmd.path = value
-
-		case "type":
-			value = strings.ToUpper(value)
-			if value != "P" && value != "E" && value != "L" {
-				return "", errors.New("ERROR: el tipo debe ser P, E o L")
-			}
-			cmd.typ = value
-
-		case "name":
-			if value == "" {
-				return "", errors.New("ERROR: el nombre no puede estar vacío")
-			}
-			cmd.name = value
-
-		case "delete":
-			value = strings.ToLower(value)
-			if value != "fast" && value != "full" {
-				return "", errors.New("ERROR: delete debe ser 'fast' o 'full'")
-			}
-			cmd.delete = value
+	cmd.undo = params.Has("undo")
 
-		case "add":
-			add, err := strconv.Atoi(value)
-			if err != nil {
-				return "", errors.New("ERROR: add debe ser un número entero")
-			}
-			cmd.add = add
+	// Verificar que el disco existe (archivo o disco en memoria)
+	dev, err := stores.OpenDevice(cmd.path)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("ERROR: el disco no existe en la ruta: %s", cmd.path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("ERROR: error abriendo el disco: %v", err)
+	}
+	cmd.dev = dev
 
-		default:
-			return "", fmt.Errorf("ERROR: parámetro desconocido: -%s", key)
+	if cmd.undo {
+		if cmd.dryRun {
+			return "", errors.New("ERROR: -undo no se puede combinar con -dryrun")
 		}
+		return undoPartitionChange(cmd.path)
 	}
 
-	// Validar que path y name siempre estén presentes
-	if cmd.path == "" {
-		return "", errors.New("ERROR: faltan parámetros requeridos: -path")
-	}
+	// -name solo es opcional al deshacer
 	if cmd.name == "" {
 		return "", errors.New("ERROR: faltan parámetros requeridos: -name")
 	}
 
-	// Verificar que el archivo existe
-	if _, err := os.Stat(cmd.path); os.IsNotExist(err) {
-		return "", fmt.Errorf("ERROR: el disco no existe en la ruta: %s", cmd.path)
-	}
-
 	// Determinar la operación a realizar
 	if cmd.delete != "" {
 		return deletePartition(cmd)
@@ -154,7 +113,7 @@ func createPartition(cmd *FDISK) (string, error) {
 
 	// Leer MBR
 	var mbr structures.MBR
-	if err := mbr.Deserialize(cmd.path); err != nil {
+	if err := mbr.Deserialize(cmd.dev); err != nil {
 		return "", fmt.Errorf("ERROR: error leyendo MBR: %v", err)
 	}
 
@@ -173,17 +132,35 @@ func createPartition(cmd *FDISK) (string, error) {
 		return "", err
 	}
 
+	if cmd.dryRun {
+		return planCreatePartition(cmd, &mbr, sizeBytes), nil
+	}
+
+	snapshot, err := takePartitionSnapshot(cmd.path)
+	if err != nil {
+		return "", err
+	}
+
 	// Crear según el tipo
+	var msg string
 	switch cmd.typ {
 	case "P":
-		return createPrimaryPartition(cmd, &mbr, sizeBytes)
+		msg, err = createPrimaryPartition(cmd, &mbr, sizeBytes)
 	case "E":
-		return createExtendedPartition(cmd, &mbr, sizeBytes)
+		msg, err = createExtendedPartition(cmd, &mbr, sizeBytes)
 	case "L":
-		return createLogicalPartition(cmd, &mbr, sizeBytes)
+		msg, err = createLogicalPartition(cmd, &mbr, sizeBytes)
 	default:
 		return "", errors.New("ERROR: tipo de partición no válido")
 	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := saveUndoSnapshot(cmd.path, snapshot, fmt.Sprintf("crear partición '%s'", cmd.name)); err != nil {
+		return undoWarning(msg, err), nil
+	}
+	return msg, nil
 }
 
 // checkLogicalPartitionNames verifica que el nombre no exista en particiones lógicas
@@ -206,7 +183,7 @@ func checkLogicalPartitionNames(cmd *FDISK, mbr *structures.MBR) error {
 
 	// Leer el primer EBR
 	currentEBR := structures.EBR{}
-	if err := currentEBR.Deserialize(cmd.path, extStart); err != nil {
+	if err := currentEBR.Deserialize(cmd.dev, extStart); err != nil {
 		return nil // Si no se puede leer, no hay lógicas
 	}
 
@@ -225,7 +202,7 @@ func checkLogicalPartitionNames(cmd *FDISK, mbr *structures.MBR) error {
 		}
 
 		currentPos = int(currentEBR.Part_next)
-		if err := currentEBR.Deserialize(cmd.path, currentPos); err != nil {
+		if err := currentEBR.Deserialize(cmd.dev, currentPos); err != nil {
 			break
 		}
 	}
@@ -272,7 +249,7 @@ func createPrimaryPartition(cmd *FDISK, mbr *structures.MBR, sizeBytes int) (str
 	mbr.Mbr_partitions[partIndex].CreatePartition(startByte, sizeBytes, "P", cmd.fit, cmd.name)
 
 	// Serializar el MBR
-	if err := mbr.Serialize(cmd.path); err != nil {
+	if err := mbr.Serialize(cmd.dev); err != nil {
 		return "", fmt.Errorf("ERROR: error escribiendo MBR: %v", err)
 	}
 
@@ -338,12 +315,12 @@ func createExtendedPartition(cmd *FDISK, mbr *structures.MBR, sizeBytes int) (st
 	}
 
 	// Escribir el EBR al inicio de la partición extendida
-	if err := ebr.Serialize(cmd.path, startByte); err != nil {
+	if err := ebr.Serialize(cmd.dev, startByte); err != nil {
 		return "", fmt.Errorf("ERROR: error escribiendo EBR inicial: %v", err)
 	}
 
 	// Serializar el MBR
-	if err := mbr.Serialize(cmd.path); err != nil {
+	if err := mbr.Serialize(cmd.dev); err != nil {
 		return "", fmt.Errorf("ERROR: error escribiendo MBR: %v", err)
 	}
 
@@ -382,21 +359,23 @@ func createLogicalPartition(cmd *FDISK, mbr *structures.MBR, sizeBytes int) (str
 
 	// Leer el primer EBR
 	currentEBR := structures.EBR{}
-	if err := currentEBR.Deserialize(cmd.path, extStart); err != nil {
+	if err := currentEBR.Deserialize(cmd.dev, extStart); err != nil {
 		return "", fmt.Errorf("ERROR: error leyendo primer EBR: %v", err)
 	}
 
-	// Si el primer EBR está vacío (primera partición lógica)
-	if currentEBR.Part_start == -1 {
-		currentEBR.Part_mount = [1]byte{'1'}
+	// Si el primer EBR está vacío (primera partición lógica, o se eliminó la primera
+	// y el EBR sigue apuntando al resto de la cadena) se reutiliza si cabe
+	if currentEBR.Part_start == -1 &&
+		(currentEBR.Part_next == -1 || extStart+ebrSize+sizeBytes <= int(currentEBR.Part_next)) {
+		currentEBR.Part_mount = [1]byte{'0'}
 		currentEBR.Part_fit = [1]byte{cmd.fit[0]}
-		currentEBR.Part_start = int32(extStart + ebrSize)
-		currentEBR.Part_size = int32(sizeBytes)
-		currentEBR.Part_next = -1
+		currentEBR.Part_start = int64(extStart + ebrSize)
+		currentEBR.Part_size = int64(sizeBytes)
+		currentEBR.Part_name = [16]byte{}
 		copy(currentEBR.Part_name[:], cmd.name)
 
 		// Escribir el EBR actualizado
-		if err := currentEBR.Serialize(cmd.path, extStart); err != nil {
+		if err := currentEBR.Serialize(cmd.dev, extStart); err != nil {
 			return "", fmt.Errorf("ERROR: error escribiendo EBR: %v", err)
 		}
 
@@ -412,7 +391,7 @@ func createLogicalPartition(cmd *FDISK, mbr *structures.MBR, sizeBytes int) (str
 	currentPos := extStart
 	for currentEBR.Part_next != -1 {
 		currentPos = int(currentEBR.Part_next)
-		if err := currentEBR.Deserialize(cmd.path, currentPos); err != nil {
+		if err := currentEBR.Deserialize(cmd.dev, currentPos); err != nil {
 			return "", fmt.Errorf("ERROR: error leyendo siguiente EBR: %v", err)
 		}
 	}
@@ -426,25 +405,25 @@ func createLogicalPartition(cmd *FDISK, mbr *structures.MBR, sizeBytes int) (str
 	}
 
 	// Actualizar el EBR actual para que apunte al nuevo
-	currentEBR.Part_next = int32(nextEBRPos)
+	currentEBR.Part_next = int64(nextEBRPos)
 
 	// Escribir el EBR actualizado
-	if err := currentEBR.Serialize(cmd.path, currentPos); err != nil {
+	if err := currentEBR.Serialize(cmd.dev, currentPos); err != nil {
 		return "", fmt.Errorf("ERROR: error actualizando EBR: %v", err)
 	}
 
 	// Crear el nuevo EBR
 	newEBR := structures.EBR{
-		Part_mount: [1]byte{'1'},
+		Part_mount: [1]byte{'0'},
 		Part_fit:   [1]byte{cmd.fit[0]},
-		Part_start: int32(nextEBRPos + ebrSize),
-		Part_size:  int32(sizeBytes),
+		Part_start: int64(nextEBRPos + ebrSize),
+		Part_size:  int64(sizeBytes),
 		Part_next:  -1,
 	}
 	copy(newEBR.Part_name[:], cmd.name)
 
 	// Escribir el nuevo EBR
-	if err := newEBR.Serialize(cmd.path, nextEBRPos); err != nil {
+	if err := newEBR.Serialize(cmd.dev, nextEBRPos); err != nil {
 		return "", fmt.Errorf("ERROR: error escribiendo nuevo EBR: %v", err)
 	}
 
@@ -554,7 +533,7 @@ func findAvailableSpace(mbr *structures.MBR, sizeBytes int, fit string) (int, er
 // deletePartition elimina una partición
 func deletePartition(cmd *FDISK) (string, error) {
 	var mbr structures.MBR
-	if err := mbr.Deserialize(cmd.path); err != nil {
+	if err := mbr.Deserialize(cmd.dev); err != nil {
 		return "", fmt.Errorf("ERROR: error leyendo MBR: %v", err)
 	}
 
@@ -569,41 +548,35 @@ func deletePartition(cmd *FDISK) (string, error) {
 	}
 
 	if partIndex == -1 {
+		// Si no es primaria ni extendida puede ser una lógica
+		for i := 0; i < 4; i++ {
+			if mbr.Mbr_partitions[i].Part_start != -1 && mbr.Mbr_partitions[i].Part_type[0] == 'E' {
+				return deleteLogicalPartition(cmd, int64(mbr.Mbr_partitions[i].Part_start))
+			}
+		}
 		return "", fmt.Errorf("ERROR: no existe la partición '%s'", cmd.name)
 	}
 
 	partition := &mbr.Mbr_partitions[partIndex]
 
-	// Si es extendida y delete=full, limpiar todo
-	if partition.Part_type[0] == 'E' && cmd.delete == "full" {
-		file, err := os.OpenFile(cmd.path, os.O_WRONLY, 0644)
-		if err != nil {
-			return "", fmt.Errorf("ERROR: error abriendo el disco: %v", err)
-		}
-		defer file.Close()
-
-		if _, err := file.Seek(int64(partition.Part_start), 0); err != nil {
-			return "", fmt.Errorf("ERROR: error posicionando en el disco: %v", err)
-		}
+	// En una extendida también cuentan las lógicas montadas
+	mounted := mountedIDsIn(cmd.path, int64(partition.Part_start), int64(partition.Part_start)+int64(partition.Part_size))
 
-		zeros := make([]byte, partition.Part_size)
-		if _, err := file.Write(zeros); err != nil {
-			return "", fmt.Errorf("ERROR: error limpiando la partición: %v", err)
-		}
-	} else if cmd.delete == "full" {
-		// Llenar con \0 la partición
-		file, err := os.OpenFile(cmd.path, os.O_WRONLY, 0644)
-		if err != nil {
-			return "", fmt.Errorf("ERROR: error abriendo el disco: %v", err)
-		}
-		defer file.Close()
+	if cmd.dryRun {
+		return planDeletePartition(cmd, partIndex, partition, mounted), nil
+	}
+	if err := checkDeleteMounted(cmd, mounted); err != nil {
+		return "", err
+	}
 
-		if _, err := file.Seek(int64(partition.Part_start), 0); err != nil {
-			return "", fmt.Errorf("ERROR: error posicionando en el disco: %v", err)
-		}
+	snapshot, err := takePartitionSnapshot(cmd.path)
+	if err != nil {
+		return "", err
+	}
 
-		zeros := make([]byte, partition.Part_size)
-		if _, err := file.Write(zeros); err != nil {
+	// delete=full llena con \0 la partición (en una extendida, también sus lógicas)
+	if cmd.delete == "full" {
+		if err := utils.ZeroFill(cmd.dev, int64(partition.Part_start), int64(partition.Part_size)); err != nil {
 			return "", fmt.Errorf("ERROR: error limpiando la partición: %v", err)
 		}
 	}
@@ -623,11 +596,68 @@ func deletePartition(cmd *FDISK) (string, error) {
 	}
 
 	// Serializar el MBR
-	if err := mbr.Serialize(cmd.path); err != nil {
+	if err := mbr.Serialize(cmd.dev); err != nil {
 		return "", fmt.Errorf("ERROR: error escribiendo MBR: %v", err)
 	}
 
-	return fmt.Sprintf("FDISK: Partición '%s' eliminada correctamente (%s)", cmd.name, cmd.delete), nil
+	msg := fmt.Sprintf("FDISK: Partición '%s' eliminada correctamente (%s)", cmd.name, cmd.delete)
+	// Con delete=full los datos ya no existen: restaurar la tabla apuntaría a ceros
+	if cmd.delete == "full" {
+		snapshot.Blocked = "los datos de la partición se llenaron con ceros"
+	}
+	if err := saveUndoSnapshot(cmd.path, snapshot, fmt.Sprintf("eliminar partición '%s' (%s)", cmd.name, cmd.delete)); err != nil {
+		msg = undoWarning(msg, err)
+	}
+	return releaseDeletedMounts(msg, mounted), nil
+}
+
+// mountedIDsIn IDs montados del disco cuyas particiones empiezan en [start, end)
+func mountedIDsIn(diskPath string, start, end int64) []string {
+	var ids []string
+	for _, m := range mountedPartitions(diskPath) {
+		if m.partition.Part_start >= start && m.partition.Part_start < end {
+			ids = append(ids, m.id)
+		}
+	}
+	return ids
+}
+
+// checkDeleteMounted rechaza eliminar una partición montada si no se usó -force: el
+// montaje y la sesión quedarían apuntando a una partición que ya no existe
+func checkDeleteMounted(cmd *FDISK, mounted []string) error {
+	if len(mounted) > 0 && !cmd.force {
+		return fmt.Errorf("ERROR: hay particiones montadas en '%s' (%s); desmóntelas o use -force", cmd.name, strings.Join(mounted, ", "))
+	}
+	return nil
+}
+
+// releaseDeletedMounts quita los montajes de la partición eliminada, cerrando la sesión
+// si estaba en uno de ellos, y lo agrega al mensaje
+func releaseDeletedMounts(msg string, mounted []string) string {
+	if len(mounted) == 0 {
+		return msg
+	}
+	sessionUser := dropMounts(mounted)
+	msg += fmt.Sprintf("\n-> Se desmontaron: %s", strings.Join(mounted, ", "))
+	if sessionUser != "" {
+		msg += fmt.Sprintf("\n-> Se cerró la sesión de '%s'", sessionUser)
+	}
+	return msg
+}
+
+// addDeleteMountNotes informa qué pasaría con los montajes de la partición a eliminar
+func addDeleteMountNotes(plan *dryRunPlan, cmd *FDISK, mounted []string) {
+	if len(mounted) == 0 {
+		return
+	}
+	if !cmd.force {
+		plan.addNote("se rechazaría: hay particiones montadas (%s); use -force", strings.Join(mounted, ", "))
+		return
+	}
+	plan.addNote("se desmontarían: %s", strings.Join(mounted, ", "))
+	if username, partitionID := stores.Auth.GetCurrentUser(); stores.Auth.IsAuthenticated() && slices.Contains(mounted, strings.ToUpper(partitionID)) {
+		plan.addNote("se cerraría la sesión de '%s'", username)
+	}
 }
 
 // modifyPartitionSize modifica el tamaño de una partición
@@ -643,26 +673,17 @@ func modifyPartitionSize(cmd *FDISK) (string, error) {
 	}
 
 	var mbr structures.MBR
-	if err := mbr.Deserialize(cmd.path); err != nil {
+	if err := mbr.Deserialize(cmd.dev); err != nil {
 		return "", fmt.Errorf("ERROR: error leyendo MBR: %v", err)
 	}
 
-	// Buscar la partición
-	partIndex := -1
-	for i := 0; i < 4; i++ {
-		partName := strings.TrimRight(string(mbr.Mbr_partitions[i].Part_name[:]), "\x00")
-		if partName == cmd.name && mbr.Mbr_partitions[i].Part_start != -1 {
-			partIndex = i
-			break
-		}
-	}
-
-	if partIndex == -1 {
-		return "", fmt.Errorf("ERROR: no existe la partición '%s'", cmd.name)
+	// Buscar la partición entre las primarias y las lógicas
+	target, err := findResizeTarget(cmd.dev, &mbr, cmd.name)
+	if err != nil {
+		return "", err
 	}
 
-	partition := &mbr.Mbr_partitions[partIndex]
-	newSize := int(partition.Part_size) + addBytes
+	newSize := target.size + int64(addBytes)
 
 	// Verificar que el nuevo tamaño sea positivo
 	if newSize <= 0 {
@@ -670,32 +691,47 @@ func modifyPartitionSize(cmd *FDISK) (string, error) {
 	}
 
 	// Si se está agregando espacio, verificar que haya espacio disponible después
-	if addBytes > 0 {
-		partEnd := int(partition.Part_start) + int(partition.Part_size)
-		nextPartStart := int(mbr.Mbr_size)
+	if addBytes > 0 && target.start+newSize > target.limit {
+		return "", errors.New("ERROR: no hay suficiente espacio contiguo disponible")
+	}
 
-		// Buscar la siguiente partición
-		for i := 0; i < 4; i++ {
-			if i != partIndex && mbr.Mbr_partitions[i].Part_start != -1 {
-				if int(mbr.Mbr_partitions[i].Part_start) > partEnd &&
-					int(mbr.Mbr_partitions[i].Part_start) < nextPartStart {
-					nextPartStart = int(mbr.Mbr_partitions[i].Part_start)
-				}
+	// Las estructuras EXT2/EXT3 se reubican para el nuevo tamaño; al reducir se
+	// rechaza el cambio si quedarían fuera inodos o bloques en uso
+	var fsResize *filesystemResize
+	if !target.extended {
+		fsResize, err = planFilesystemResize(cmd.dev, target.start, newSize)
+		if err != nil {
+			return "", err
+		}
+	} else if addBytes < 0 {
+		// Al reducir la extendida ninguna lógica puede quedar fuera (también en dryrun,
+		// para que el plan no informe un cambio que se rechazaría)
+		for _, logical := range readLogicalPartitions(cmd.dev, target.start) {
+			if int64(logical.Part_start)+int64(logical.Part_size) > target.start+newSize {
+				name := strings.TrimRight(string(logical.Part_name[:]), "\x00")
+				return "", fmt.Errorf("ERROR: no se puede reducir: la lógica '%s' quedaría fuera de la extendida", name)
 			}
 		}
+	}
 
-		availableSpace := nextPartStart - partEnd
-		if addBytes > availableSpace {
-			return "", errors.New("ERROR: no hay suficiente espacio contiguo disponible")
-		}
+	if cmd.dryRun {
+		return planResizePartition(cmd, &mbr, target, newSize, fsResize), nil
+	}
+
+	snapshot, err := takePartitionSnapshot(cmd.path)
+	if err != nil {
+		return "", err
 	}
 
-	// Actualizar el tamaño
-	partition.Part_size = int32(newSize)
+	if fsResize != nil {
+		if err := fsResize.apply(cmd.dev); err != nil {
+			return "", err
+		}
+	}
 
-	// Serializar el MBR
-	if err := mbr.Serialize(cmd.path); err != nil {
-		return "", fmt.Errorf("ERROR: error escribiendo MBR: %v", err)
+	// Actualizar el tamaño en el MBR o en el EBR
+	if err := target.writeSize(cmd.dev, &mbr, newSize); err != nil {
+		return "", err
 	}
 
 	operation := "agregados"
@@ -703,6 +739,81 @@ func modifyPartitionSize(cmd *FDISK) (string, error) {
 		operation = "removidos"
 	}
 
-	return fmt.Sprintf("FDISK: Partición '%s' modificada correctamente (%d bytes %s)",
-		cmd.name, utils.Abs(addBytes), operation), nil
+	msg := fmt.Sprintf("FDISK: Partición '%s' modificada correctamente (%d bytes %s)",
+		cmd.name, utils.Abs(addBytes), operation)
+	if fsResize != nil {
+		msg += fmt.Sprintf("\n-> %s: %d inodos y %d bloques", fsResize.fsName, fsResize.newInodes, 3*fsResize.newInodes)
+	}
+	// Volver al tamaño anterior dejaría el sistema de archivos reubicado fuera de lugar
+	if fsResize != nil {
+		snapshot.Blocked = fmt.Sprintf("se reubicó el sistema de archivos %s", fsResize.fsName)
+	}
+	if err := saveUndoSnapshot(cmd.path, snapshot, fmt.Sprintf("cambiar tamaño de '%s' (%+d bytes)", cmd.name, addBytes)); err != nil {
+		msg = undoWarning(msg, err)
+	}
+	return msg, nil
+}
+
+// planCreatePartition informa la partición que se crearía (crear no destruye datos)
+func planCreatePartition(cmd *FDISK, mbr *structures.MBR, sizeBytes int) string {
+	plan := newDryRunPlan("FDISK")
+
+	if cmd.typ == "L" {
+		plan.addMBRChange("se agregaría un EBR para la lógica '%s' de %d bytes al final de la cadena", cmd.name, sizeBytes)
+		return plan.String()
+	}
+
+	start, err := findAvailableSpace(mbr, sizeBytes, cmd.fit)
+	if err != nil {
+		plan.addNote("la partición no se podría crear: %v", err)
+		return plan.String()
+	}
+
+	if _, _, index := mbr.GetFirstAvailablePartition(); index != -1 {
+		plan.addMBRChange("entrada %d vacía -> '%s' (tipo %s, inicio %d, tamaño %d)", index, cmd.name, cmd.typ, start, sizeBytes)
+	}
+	if cmd.typ == "E" {
+		plan.addRange(int64(start), int64(start+binary.Size(structures.EBR{})), "EBR inicial vacío de la extendida")
+	}
+	return plan.String()
+}
+
+// planDeletePartition informa lo que se perdería al eliminar la partición
+func planDeletePartition(cmd *FDISK, index int, partition *structures.Partition, mounted []string) string {
+	plan := newDryRunPlan("FDISK")
+	start := int64(partition.Part_start)
+	end := start + int64(partition.Part_size)
+
+	plan.addMBRChange("%s -> vacía", describePartition(index, partition))
+	if cmd.delete == "full" {
+		plan.addRange(start, end, fmt.Sprintf("se llenaría con ceros la partición '%s'", cmd.name))
+	}
+
+	if partition.Part_type[0] == 'E' {
+		plan.addLogicalPartitions(cmd.dev, start, false)
+	} else {
+		plan.addFreedInodes(cmd.dev, cmd.name, start, -1)
+	}
+	addDeleteMountNotes(plan, cmd, mounted)
+	if cmd.delete == "fast" {
+		plan.addNote("delete=fast solo modifica el MBR; los datos quedan en el disco")
+	}
+	return plan.String()
+}
+
+// planResizePartition informa el cambio de tamaño y, si se reduce, el espacio que se libera
+func planResizePartition(cmd *FDISK, mbr *structures.MBR, target *resizeTarget, newSize int64, fsResize *filesystemResize) string {
+	plan := newDryRunPlan("FDISK")
+	oldEnd := target.start + target.size
+	newEnd := target.start + newSize
+
+	plan.addMBRChange("%s -> tamaño %d", target.describe(mbr), newSize)
+
+	if newEnd < oldEnd {
+		plan.addNote("[%d, %d) %d bytes quedarían fuera de la partición '%s'", newEnd, oldEnd, oldEnd-newEnd, cmd.name)
+	}
+	if fsResize != nil {
+		fsResize.addToPlan(plan, cmd.name)
+	}
+	return plan.String()
 }
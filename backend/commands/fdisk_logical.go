@@ -0,0 +1,118 @@
+package commands
+
+import (
+	structures "backend/structures"
+	utils "backend/utils"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// logicalEntry lógica de la cadena de EBR con su posición y la del EBR anterior
+type logicalEntry struct {
+	ebr      structures.EBR
+	position int64
+	previous int64 // -1 si es el primer EBR de la extendida
+}
+
+// findLogicalEntry busca una lógica por nombre en la cadena que empieza en extStart
+func findLogicalEntry(dev structures.Device, extStart int64, name string) (*logicalEntry, error) {
+	previous := int64(-1)
+	for _, position := range ebrChainPositions(dev, extStart) {
+		ebr := structures.EBR{}
+		if err := ebr.Deserialize(dev, int(position)); err != nil {
+			return nil, fmt.Errorf("ERROR: error leyendo EBR: %v", err)
+		}
+		if !ebr.IsEmpty() && strings.TrimRight(string(ebr.Part_name[:]), "\x00") == name {
+			return &logicalEntry{ebr: ebr, position: position, previous: previous}, nil
+		}
+		previous = position
+	}
+	return nil, fmt.Errorf("ERROR: no existe la partición '%s'", name)
+}
+
+// deleteLogicalPartition elimina una lógica desenlazando su EBR: el EBR anterior pasa a
+// apuntar al siguiente. El primer EBR no se puede quitar de la cadena, así que se vacía
+// conservando Part_next.
+func deleteLogicalPartition(cmd *FDISK, extStart int64) (string, error) {
+	entry, err := findLogicalEntry(cmd.dev, extStart, cmd.name)
+	if err != nil {
+		return "", err
+	}
+
+	start := int64(entry.ebr.Part_start)
+	mounted := mountedIDsIn(cmd.path, start, start+int64(entry.ebr.Part_size))
+
+	if cmd.dryRun {
+		return planDeleteLogicalPartition(cmd, entry, mounted), nil
+	}
+	if err := checkDeleteMounted(cmd, mounted); err != nil {
+		return "", err
+	}
+
+	snapshot, err := takePartitionSnapshot(cmd.path)
+	if err != nil {
+		return "", err
+	}
+
+	if cmd.delete == "full" {
+		if err := utils.ZeroFill(cmd.dev, int64(entry.ebr.Part_start), int64(entry.ebr.Part_size)); err != nil {
+			return "", fmt.Errorf("ERROR: error limpiando la partición: %v", err)
+		}
+	}
+
+	if entry.previous == -1 {
+		next := entry.ebr.Part_next
+		entry.ebr.Clear()
+		entry.ebr.Part_next = next
+		if err := entry.ebr.Serialize(cmd.dev, int(entry.position)); err != nil {
+			return "", fmt.Errorf("ERROR: error escribiendo EBR: %v", err)
+		}
+	} else {
+		previous := structures.EBR{}
+		if err := previous.Deserialize(cmd.dev, int(entry.previous)); err != nil {
+			return "", fmt.Errorf("ERROR: error leyendo EBR anterior: %v", err)
+		}
+		previous.Part_next = entry.ebr.Part_next
+		if err := previous.Serialize(cmd.dev, int(entry.previous)); err != nil {
+			return "", fmt.Errorf("ERROR: error actualizando EBR anterior: %v", err)
+		}
+
+		// El EBR desenlazado se vacía para que no parezca una lógica al leer el disco
+		entry.ebr.Clear()
+		if err := entry.ebr.Serialize(cmd.dev, int(entry.position)); err != nil {
+			return "", fmt.Errorf("ERROR: error limpiando EBR: %v", err)
+		}
+	}
+
+	msg := fmt.Sprintf("FDISK: Partición lógica '%s' eliminada correctamente (%s)", cmd.name, cmd.delete)
+	// Con delete=full los datos ya no existen: restaurar la tabla apuntaría a ceros
+	if cmd.delete == "full" {
+		snapshot.Blocked = "los datos de la partición se llenaron con ceros"
+	}
+	if err := saveUndoSnapshot(cmd.path, snapshot, fmt.Sprintf("eliminar partición lógica '%s' (%s)", cmd.name, cmd.delete)); err != nil {
+		msg = undoWarning(msg, err)
+	}
+	return releaseDeletedMounts(msg, mounted), nil
+}
+
+// planDeleteLogicalPartition informa lo que se perdería al eliminar la lógica
+func planDeleteLogicalPartition(cmd *FDISK, entry *logicalEntry, mounted []string) string {
+	plan := newDryRunPlan("FDISK")
+	start := int64(entry.ebr.Part_start)
+	end := start + int64(entry.ebr.Part_size)
+	ebrSize := int64(binary.Size(structures.EBR{}))
+
+	if entry.previous == -1 {
+		plan.addMBRChange("EBR en %d de la lógica '%s' -> vacío (sigue apuntando a %d)", entry.position, cmd.name, entry.ebr.Part_next)
+	} else {
+		plan.addMBRChange("EBR anterior en %d -> Part_next %d", entry.previous, entry.ebr.Part_next)
+		plan.addRange(entry.position, entry.position+ebrSize, fmt.Sprintf("EBR desenlazado de la lógica '%s'", cmd.name))
+	}
+	if cmd.delete == "full" {
+		plan.addRange(start, end, fmt.Sprintf("se llenaría con ceros la lógica '%s'", cmd.name))
+	}
+	plan.addFreedInodes(cmd.dev, cmd.name, start, -1)
+	addDeleteMountNotes(plan, cmd, mounted)
+	return plan.String()
+}
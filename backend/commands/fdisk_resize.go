@@ -0,0 +1,410 @@
+package commands
+
+import (
+	structures "backend/structures"
+	utils "backend/utils"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// resizeTarget partición (primaria, extendida o lógica) a la que se le cambia el tamaño con -add
+type resizeTarget struct {
+	name      string
+	start     int64
+	size      int64
+	limit     int64 // Byte hasta el que puede crecer sin pisar otra partición
+	extended  bool
+	partIndex int   // Entrada del MBR; -1 si es lógica
+	ebrPos    int64 // Posición del EBR si es lógica
+	ebr       structures.EBR
+}
+
+// findResizeTarget busca la partición por nombre en el MBR y, si no está, en la cadena de EBR
+func findResizeTarget(dev structures.Device, mbr *structures.MBR, name string) (*resizeTarget, error) {
+	extIndex := -1
+	for i := 0; i < 4; i++ {
+		partition := &mbr.Mbr_partitions[i]
+		if partition.Part_start == -1 {
+			continue
+		}
+		if partition.Part_type[0] == 'E' {
+			extIndex = i
+		}
+		if strings.TrimRight(string(partition.Part_name[:]), "\x00") != name {
+			continue
+		}
+
+		// Puede crecer hasta la siguiente partición o el final del disco
+		start := int64(partition.Part_start)
+		end := start + int64(partition.Part_size)
+		limit := int64(mbr.Mbr_size)
+		for j := 0; j < 4; j++ {
+			other := &mbr.Mbr_partitions[j]
+			if j != i && other.Part_start != -1 && int64(other.Part_start) >= end && int64(other.Part_start) < limit {
+				limit = int64(other.Part_start)
+			}
+		}
+
+		return &resizeTarget{
+			name:      name,
+			start:     start,
+			size:      int64(partition.Part_size),
+			limit:     limit,
+			extended:  partition.Part_type[0] == 'E',
+			partIndex: i,
+		}, nil
+	}
+
+	if extIndex == -1 {
+		return nil, fmt.Errorf("ERROR: no existe la partición '%s'", name)
+	}
+
+	extended := &mbr.Mbr_partitions[extIndex]
+	extEnd := int64(extended.Part_start) + int64(extended.Part_size)
+	for _, position := range ebrChainPositions(dev, int64(extended.Part_start)) {
+		ebr := structures.EBR{}
+		if err := ebr.Deserialize(dev, int(position)); err != nil {
+			return nil, fmt.Errorf("ERROR: error leyendo EBR: %v", err)
+		}
+		if ebr.IsEmpty() || strings.TrimRight(string(ebr.Part_name[:]), "\x00") != name {
+			continue
+		}
+
+		// Una lógica puede crecer hasta el siguiente EBR o el final de la extendida
+		limit := extEnd
+		if ebr.Part_next != -1 {
+			limit = int64(ebr.Part_next)
+		}
+
+		return &resizeTarget{
+			name:      name,
+			start:     int64(ebr.Part_start),
+			size:      int64(ebr.Part_size),
+			limit:     limit,
+			partIndex: -1,
+			ebrPos:    position,
+			ebr:       ebr,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("ERROR: no existe la partición '%s'", name)
+}
+
+// describe descripción de la entrada del MBR o del EBR para el plan
+func (t *resizeTarget) describe(mbr *structures.MBR) string {
+	if t.partIndex >= 0 {
+		return describePartition(t.partIndex, &mbr.Mbr_partitions[t.partIndex])
+	}
+	return fmt.Sprintf("EBR en %d de la lógica '%s' (inicio %d, tamaño %d)", t.ebrPos, t.name, t.start, t.size)
+}
+
+// writeSize guarda el nuevo tamaño en el MBR o en el EBR de la lógica
+func (t *resizeTarget) writeSize(dev structures.Device, mbr *structures.MBR, newSize int64) error {
+	if t.partIndex >= 0 {
+		mbr.Mbr_partitions[t.partIndex].Part_size = newSize
+		if err := mbr.Serialize(dev); err != nil {
+			return fmt.Errorf("ERROR: error escribiendo MBR: %v", err)
+		}
+		return nil
+	}
+
+	t.ebr.Part_size = newSize
+	if err := t.ebr.Serialize(dev, int(t.ebrPos)); err != nil {
+		return fmt.Errorf("ERROR: error escribiendo EBR: %v", err)
+	}
+	return nil
+}
+
+// filesystemResize reubicación de las estructuras EXT2/EXT3 para el nuevo tamaño de la
+// partición. Los índices de inodos y bloques no cambian, así que I_block y B_inodo
+// siguen siendo válidos; solo se mueven las tablas y crecen o se recortan.
+type filesystemResize struct {
+	partStart int64
+	fsName    string
+	inodes    int64 // Inodos de la tabla actual (los bloques son el triple)
+	newInodes int64
+	old       structures.SuperBlock
+	sb        structures.SuperBlock // Superbloque con la nueva distribución
+}
+
+// planFilesystemResize calcula la nueva distribución del sistema de archivos de la
+// partición. Devuelve nil si la partición no está formateada o n no cambia, y un error
+// si al reducir quedarían fuera inodos o bloques en uso.
+func planFilesystemResize(dev structures.Device, partStart, newSize int64) (*filesystemResize, error) {
+	sb := structures.SuperBlock{}
+	if err := sb.Deserialize(dev, partStart); err != nil || sb.S_magic != 0xEF53 {
+		return nil, nil
+	}
+
+	fsName := "EXT2"
+	if sb.S_filesystem_type == 3 {
+		fsName = "EXT3"
+	}
+
+	// El tamaño de la tabla sale de la distribución (S_inodes_count cambia al crear inodos)
+	layout, err := sb.Layout(partStart, newSize)
+	if err != nil {
+		return nil, fmt.Errorf("ERROR: el sistema de archivos de la partición no tiene la distribución esperada: %v", err)
+	}
+	inodes := layout.Inodes
+
+	// Se conservan los tamaños de inodo y de journal con los que se formateó
+	newLayout := layout.Resize(newSize)
+	newInodes := newLayout.Inodes
+	if newInodes == inodes {
+		return nil, nil
+	}
+	if !newLayout.Fits() {
+		return nil, fmt.Errorf("ERROR: la partición quedaría demasiado pequeña para el sistema de archivos %s", fsName)
+	}
+
+	if newInodes < inodes {
+		maxInode, maxBlock, err := highestUsedIndexes(dev, &sb, inodes)
+		if err != nil {
+			return nil, err
+		}
+		if maxInode >= newInodes {
+			return nil, fmt.Errorf("ERROR: no se puede reducir: el inodo %d está en uso y solo quedarían %d inodos", maxInode, newInodes)
+		}
+		if maxBlock >= 3*newInodes {
+			return nil, fmt.Errorf("ERROR: no se puede reducir: el bloque %d está en uso y solo quedarían %d bloques", maxBlock, 3*newInodes)
+		}
+	}
+
+	resize := &filesystemResize{
+		partStart: partStart,
+		fsName:    fsName,
+		inodes:    inodes,
+		newInodes: newInodes,
+		old:       sb,
+		sb:        sb,
+	}
+
+	delta := int32(newInodes - inodes)
+	resize.sb.S_inodes_count += delta
+	resize.sb.S_blocks_count += 3 * delta
+	resize.sb.S_free_inodes_count = max(resize.sb.S_free_inodes_count+delta, 0)
+	resize.sb.S_free_blocks_count = max(resize.sb.S_free_blocks_count+3*delta, 0)
+	newLayout.Apply(&resize.sb)
+	resize.sb.S_first_ino = resize.sb.S_inode_start + (sb.S_first_ino - sb.S_inode_start)
+	resize.sb.S_first_blo = resize.sb.S_block_start + (sb.S_first_blo - sb.S_block_start)
+
+	return resize, nil
+}
+
+// apply mueve las tablas a la nueva distribución y escribe el superbloque
+func (r *filesystemResize) apply(dev structures.Device) error {
+	kept := min(r.inodes, r.newInodes)
+	moves := []struct{ src, dst, length int64 }{
+		{int64(r.old.S_bm_block_start), int64(r.sb.S_bm_block_start), structures.BitmapSize(3*kept, r.sb.PackedBitmaps())},
+		{int64(r.old.S_inode_start), int64(r.sb.S_inode_start), kept * int64(r.sb.S_inode_size)},
+		{int64(r.old.S_block_start), int64(r.sb.S_block_start), 3 * kept * int64(r.sb.S_block_size)},
+	}
+
+	// Al crecer las tablas se desplazan hacia adelante: se mueve primero la última
+	// para no pisar datos que aún no se copiaron
+	if r.newInodes > r.inodes {
+		for i := len(moves) - 1; i >= 0; i-- {
+			if err := moveRange(dev, moves[i].src, moves[i].dst, moves[i].length); err != nil {
+				return fmt.Errorf("ERROR: error reubicando el sistema de archivos: %v", err)
+			}
+		}
+
+		// Los inodos y bloques agregados quedan libres en los bitmaps
+		if err := r.sb.InodeBitmap(dev).ClearFrom(r.inodes); err != nil {
+			return fmt.Errorf("ERROR: error inicializando las estructuras nuevas: %v", err)
+		}
+		if err := r.sb.BlockBitmap(dev).ClearFrom(3 * r.inodes); err != nil {
+			return fmt.Errorf("ERROR: error inicializando las estructuras nuevas: %v", err)
+		}
+
+		added := r.newInodes - r.inodes
+		tails := []struct{ start, length int64 }{
+			{r.sb.InodeOffset(r.inodes), added * int64(r.sb.S_inode_size)},
+			{r.sb.BlockOffset(3 * r.inodes), 3 * added * int64(r.sb.S_block_size)},
+		}
+		for _, tail := range tails {
+			if err := utils.ZeroFill(dev, tail.start, tail.length); err != nil {
+				return fmt.Errorf("ERROR: error inicializando las estructuras nuevas: %v", err)
+			}
+		}
+	} else {
+		for _, move := range moves {
+			if err := moveRange(dev, move.src, move.dst, move.length); err != nil {
+				return fmt.Errorf("ERROR: error reubicando el sistema de archivos: %v", err)
+			}
+		}
+	}
+
+	if err := r.sb.Serialize(dev, r.partStart); err != nil {
+		return fmt.Errorf("ERROR: error escribiendo el superbloque: %v", err)
+	}
+	return nil
+}
+
+// addToPlan registra en el plan de dry-run lo que se reescribiría del sistema de archivos
+func (r *filesystemResize) addToPlan(plan *dryRunPlan, name string) {
+	end := r.sb.BlockOffset(3 * r.newInodes)
+	plan.addRange(int64(r.sb.S_bm_block_start), end, fmt.Sprintf("bitmaps, inodos y bloques de '%s' reubicados", name))
+	plan.addRange(r.partStart, r.partStart+structures.SuperBlockSize, fmt.Sprintf("superbloque de '%s'", name))
+	plan.addNote("%s de '%s': %d -> %d inodos, %d -> %d bloques", r.fsName, name, r.inodes, r.newInodes, 3*r.inodes, 3*r.newInodes)
+}
+
+// highestUsedIndexes devuelve el mayor índice de inodo y de bloque en uso. Combina
+// los bitmaps, el recorrido desde la raíz y los punteros S_first_ino/S_first_blo,
+// porque no todas las operaciones marcan los bitmaps.
+func highestUsedIndexes(dev structures.Device, sb *structures.SuperBlock, inodes int64) (int64, int64, error) {
+	blocks := 3 * inodes
+
+	inodeBitmap, err := sb.InodeBitmap(dev).ReadAll()
+	if err != nil {
+		return 0, 0, fmt.Errorf("ERROR: no se pudo leer el bitmap de inodos: %v", err)
+	}
+	blockBitmap, err := sb.BlockBitmap(dev).ReadAll()
+	if err != nil {
+		return 0, 0, fmt.Errorf("ERROR: no se pudo leer el bitmap de bloques: %v", err)
+	}
+	maxInode, maxBlock := lastUsed(inodeBitmap), lastUsed(blockBitmap)
+
+	if sb.S_inode_size > 0 {
+		maxInode = max(maxInode, int64(sb.S_first_ino-sb.S_inode_start)/int64(sb.S_inode_size)-1)
+	}
+	if sb.S_block_size > 0 {
+		maxBlock = max(maxBlock, int64(sb.S_first_blo-sb.S_block_start)/int64(sb.S_block_size)-1)
+	}
+
+	walker := &filesystemWalker{dev: dev, sb: sb, inodes: inodes, blocks: blocks, visited: map[int64]bool{}}
+	if err := walker.walkInode(0); err != nil {
+		return 0, 0, err
+	}
+
+	return max(maxInode, walker.maxInode), max(maxBlock, walker.maxBlock), nil
+}
+
+// lastUsed índice del último objeto en uso del bitmap, o -1 si no hay ninguno
+func lastUsed(used []bool) int64 {
+	for i := len(used) - 1; i >= 0; i-- {
+		if used[i] {
+			return int64(i)
+		}
+	}
+	return -1
+}
+
+// filesystemWalker recorre los inodos alcanzables desde la raíz
+type filesystemWalker struct {
+	dev      structures.Device
+	sb       *structures.SuperBlock
+	inodes   int64
+	blocks   int64
+	visited  map[int64]bool
+	maxInode int64
+	maxBlock int64
+}
+
+func (w *filesystemWalker) walkInode(index int64) error {
+	if index < 0 || w.visited[index] {
+		return nil
+	}
+	w.visited[index] = true
+	w.maxInode = max(w.maxInode, index)
+	if index >= w.inodes {
+		return nil
+	}
+
+	inode := structures.Inode{}
+	if err := w.read(w.sb.InodeOffset(index), &inode); err != nil {
+		return fmt.Errorf("ERROR: error leyendo el inodo %d: %v", index, err)
+	}
+
+	folder := inode.I_type[0] == '0'
+	for i, block := range inode.I_block {
+		// 0-11 directos, 12 indirecto simple, 13 doble y 14 triple
+		level := max(i-11, 0)
+		if err := w.walkBlock(int64(block), level, folder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *filesystemWalker) walkBlock(index int64, level int, folder bool) error {
+	if index < 0 {
+		return nil
+	}
+	w.maxBlock = max(w.maxBlock, index)
+	if index >= w.blocks {
+		return nil
+	}
+	offset := w.sb.BlockOffset(index)
+
+	if level > 0 {
+		pointers := structures.PointerBlock{}
+		if err := w.read(offset, &pointers); err != nil {
+			return fmt.Errorf("ERROR: error leyendo el bloque %d: %v", index, err)
+		}
+		for _, pointer := range pointers.P_pointers {
+			if err := w.walkBlock(int64(pointer), level-1, folder); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if !folder {
+		return nil
+	}
+
+	content := structures.FolderBlock{}
+	if err := w.read(offset, &content); err != nil {
+		return fmt.Errorf("ERROR: error leyendo el bloque %d: %v", index, err)
+	}
+	for _, entry := range content.B_content {
+		name := strings.TrimRight(string(entry.B_name[:]), "\x00")
+		if name == "." || name == ".." {
+			continue
+		}
+		if err := w.walkInode(int64(entry.B_inodo)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *filesystemWalker) read(offset int64, data any) error {
+	buffer := make([]byte, binary.Size(data))
+	if _, err := w.dev.ReadAt(buffer, offset); err != nil {
+		return err
+	}
+	return binary.Read(bytes.NewReader(buffer), binary.LittleEndian, data)
+}
+
+// moveRange copia length bytes de src a dst aunque los rangos se superpongan
+func moveRange(dev structures.Device, src, dst, length int64) error {
+	if src == dst || length <= 0 {
+		return nil
+	}
+
+	const chunk = 64 * 1024
+	buffer := make([]byte, chunk)
+	for done := int64(0); done < length; {
+		n := min(chunk, length-done)
+		// Si el destino está después del origen se copia desde el final
+		offset := done
+		if dst > src {
+			offset = length - done - n
+		}
+		if _, err := dev.ReadAt(buffer[:n], src+offset); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if _, err := dev.WriteAt(buffer[:n], dst+offset); err != nil {
+			return err
+		}
+		done += n
+	}
+	return nil
+}
@@ -0,0 +1,426 @@
+package commands
+
+import (
+	stores "backend/stores"
+	structures "backend/structures"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Máximo de snapshots que se conservan por disco
+const maxUndoSnapshots = 10
+
+// memoryUndo snapshots de los discos en memoria, que no tienen carpeta donde guardarlos
+var memoryUndo = map[string]*undoFile{}
+
+// partitionSnapshot copia de la tabla de particiones antes de una modificación de fdisk
+type partitionSnapshot struct {
+	Time      string        `json:"time"`
+	Operation string        `json:"operation"`
+	Signature int32         `json:"signature"`         // Mbr_disk_signature del disco
+	MBR       []byte        `json:"mbr"`               // Bytes del MBR
+	EBRs      []ebrSnapshot `json:"ebrs"`              // Bytes de cada EBR de la cadena de la extendida
+	Regions   []regionHash  `json:"regions"`           // Huella de las particiones que tocó el cambio
+	Blocked   string        `json:"blocked,omitempty"` // Motivo por el que el cambio no se puede deshacer
+
+	table []regionHash // Particiones antes del cambio (sin huella), para calcular Regions
+}
+
+// ebrSnapshot bytes de un EBR y su posición en el disco
+type ebrSnapshot struct {
+	Position int64  `json:"position"`
+	Data     []byte `json:"data"`
+}
+
+// regionHash SHA-256 de la zona de datos de una partición justo después del cambio
+type regionHash struct {
+	Name  string `json:"name"`
+	Start int64  `json:"start"`
+	Size  int64  `json:"size"`
+	Hash  string `json:"hash"`
+}
+
+// undoFile contenido del archivo de snapshots junto al disco
+type undoFile struct {
+	Snapshots []partitionSnapshot `json:"snapshots"`
+}
+
+// undoPath ruta del archivo de snapshots de un disco (ej: disco.mia.undo.json)
+func undoPath(diskPath string) string {
+	return diskPath + ".undo.json"
+}
+
+// saveUndoSnapshot guarda el snapshot tomado antes de modificar la tabla, una vez hecho
+// el cambio. Solo se guarda la huella de las particiones que el cambio creó, eliminó o
+// redimensionó: escribir en otras particiones no impide deshacerlo.
+func saveUndoSnapshot(diskPath string, snapshot *partitionSnapshot, operation string) error {
+	snapshot.Operation = operation
+
+	dev, err := stores.OpenDevice(diskPath)
+	if err != nil {
+		return err
+	}
+	after, err := partitionTable(dev)
+	if err != nil {
+		return err
+	}
+	for _, region := range changedRegions(snapshot.table, after) {
+		hashed, err := hashRegion(dev, region.Name, region.Start, region.Size)
+		if err != nil {
+			return err
+		}
+		snapshot.Regions = append(snapshot.Regions, hashed)
+	}
+
+	undo, err := loadUndoFile(diskPath)
+	if err != nil {
+		return err
+	}
+
+	undo.Snapshots = append(undo.Snapshots, *snapshot)
+	if len(undo.Snapshots) > maxUndoSnapshots {
+		undo.Snapshots = undo.Snapshots[len(undo.Snapshots)-maxUndoSnapshots:]
+	}
+
+	return writeUndoFile(diskPath, undo)
+}
+
+// undoWarning agrega al mensaje de fdisk el aviso de que el cambio no se podrá deshacer
+func undoWarning(msg string, err error) string {
+	return msg + fmt.Sprintf("\n-> ADVERTENCIA: no se guardó el snapshot, el cambio no se podrá deshacer: %v", err)
+}
+
+// takePartitionSnapshot lee el MBR, la cadena de EBR y las particiones antes de un cambio
+func takePartitionSnapshot(diskPath string) (*partitionSnapshot, error) {
+	dev, err := stores.OpenDevice(diskPath)
+	if err != nil {
+		return nil, fmt.Errorf("ERROR: no se pudo tomar el snapshot para deshacer: %v", err)
+	}
+
+	var mbr structures.MBR
+	if err := mbr.Deserialize(dev); err != nil {
+		return nil, fmt.Errorf("ERROR: no se pudo tomar el snapshot para deshacer: %v", err)
+	}
+
+	snapshot := &partitionSnapshot{
+		Time:      time.Now().Format("2006-01-02 15:04:05"),
+		Signature: mbr.Mbr_disk_signature,
+	}
+
+	snapshot.MBR = make([]byte, binary.Size(structures.MBR{}))
+	if _, err := dev.ReadAt(snapshot.MBR, 0); err != nil {
+		return nil, fmt.Errorf("ERROR: no se pudo tomar el snapshot para deshacer: %v", err)
+	}
+
+	// Crear o eliminar lógicas reescribe los EBR: se guardan todos los de la cadena
+	ebrSize := int64(binary.Size(structures.EBR{}))
+	for i := range mbr.Mbr_partitions {
+		partition := &mbr.Mbr_partitions[i]
+		if partition.Part_start == -1 || partition.Part_type[0] != 'E' {
+			continue
+		}
+		for _, position := range ebrChainPositions(dev, int64(partition.Part_start)) {
+			data := make([]byte, ebrSize)
+			if _, err := dev.ReadAt(data, position); err != nil {
+				return nil, fmt.Errorf("ERROR: no se pudo tomar el snapshot para deshacer: %v", err)
+			}
+			snapshot.EBRs = append(snapshot.EBRs, ebrSnapshot{Position: position, Data: data})
+		}
+	}
+
+	if snapshot.table, err = partitionTable(dev); err != nil {
+		return nil, fmt.Errorf("ERROR: no se pudo tomar el snapshot para deshacer: %v", err)
+	}
+	return snapshot, nil
+}
+
+// partitionTable nombre, inicio y tamaño de las particiones del MBR y de las lógicas
+func partitionTable(dev structures.Device) ([]regionHash, error) {
+	var mbr structures.MBR
+	if err := mbr.Deserialize(dev); err != nil {
+		return nil, err
+	}
+
+	var table []regionHash
+	for i := range mbr.Mbr_partitions {
+		partition := &mbr.Mbr_partitions[i]
+		if partition.Part_start == -1 {
+			continue
+		}
+		name := strings.TrimRight(string(partition.Part_name[:]), "\x00")
+		table = append(table, regionHash{Name: name, Start: int64(partition.Part_start), Size: int64(partition.Part_size)})
+
+		if partition.Part_type[0] == 'E' {
+			for _, logical := range readLogicalPartitions(dev, int64(partition.Part_start)) {
+				name := strings.TrimRight(string(logical.Part_name[:]), "\x00")
+				table = append(table, regionHash{Name: name, Start: int64(logical.Part_start), Size: int64(logical.Part_size)})
+			}
+		}
+	}
+	return table, nil
+}
+
+// changedRegions particiones que están en una sola de las dos tablas: las creadas, las
+// eliminadas y las redimensionadas (con su tamaño anterior y el nuevo)
+func changedRegions(before, after []regionHash) []regionHash {
+	var changed []regionHash
+	for _, pair := range [][2][]regionHash{{before, after}, {after, before}} {
+		for _, region := range pair[0] {
+			if !slices.Contains(pair[1], region) && !slices.Contains(changed, region) {
+				changed = append(changed, region)
+			}
+		}
+	}
+	return changed
+}
+
+// undoPartitionChange restaura el último snapshot si nadie escribió en las particiones que
+// tocó el cambio
+func undoPartitionChange(diskPath string) (string, error) {
+	undo, err := loadUndoFile(diskPath)
+	if err != nil {
+		return "", err
+	}
+	if len(undo.Snapshots) == 0 {
+		return "", fmt.Errorf("ERROR: no hay cambios de fdisk para deshacer en %s", diskPath)
+	}
+	snapshot := undo.Snapshots[len(undo.Snapshots)-1]
+
+	dev, err := stores.OpenDevice(diskPath)
+	if err != nil {
+		return "", fmt.Errorf("ERROR: error abriendo el disco: %v", err)
+	}
+
+	var mbr structures.MBR
+	if err := mbr.Deserialize(dev); err != nil {
+		return "", fmt.Errorf("ERROR: error leyendo MBR: %v", err)
+	}
+	if mbr.Mbr_disk_signature != snapshot.Signature {
+		return "", errors.New("ERROR: el snapshot pertenece a otro disco (la firma no coincide)")
+	}
+
+	if snapshot.Blocked != "" {
+		return "", fmt.Errorf("ERROR: no se puede deshacer (%s): %s", snapshot.Operation, snapshot.Blocked)
+	}
+
+	// Restaurar la tabla le quitaría el ID a una partición montada que el cambio tocó
+	mounted := mountedPartitions(diskPath)
+	for _, m := range mounted {
+		for _, region := range snapshot.Regions {
+			if m.partition.Part_start >= region.Start && m.partition.Part_start < region.Start+region.Size {
+				return "", fmt.Errorf("ERROR: no se puede deshacer (%s): la partición '%s' está montada con el ID %s; desmóntela primero",
+					snapshot.Operation, region.Name, m.id)
+			}
+		}
+	}
+
+	// Si algo escribió sobre los datos desde el cambio, restaurar la tabla los corrompería
+	for _, region := range snapshot.Regions {
+		current, err := hashRegion(dev, region.Name, region.Start, region.Size)
+		if err != nil {
+			return "", fmt.Errorf("ERROR: no se pudo verificar la partición '%s': %v", region.Name, err)
+		}
+		if current.Hash != region.Hash {
+			return "", fmt.Errorf("ERROR: no se puede deshacer (%s): los datos de la partición '%s' fueron sobrescritos", snapshot.Operation, region.Name)
+		}
+	}
+
+	if _, err := dev.WriteAt(snapshot.MBR, 0); err != nil {
+		return "", fmt.Errorf("ERROR: error restaurando el MBR: %v", err)
+	}
+	for _, ebr := range snapshot.EBRs {
+		if _, err := dev.WriteAt(ebr.Data, ebr.Position); err != nil {
+			return "", fmt.Errorf("ERROR: error restaurando el EBR en %d: %v", ebr.Position, err)
+		}
+	}
+	gone, err := restoreMounts(dev, mounted)
+	if err != nil {
+		return "", fmt.Errorf("ERROR: error restaurando los montajes: %v", err)
+	}
+	if err := dev.Flush(); err != nil {
+		return "", fmt.Errorf("ERROR: error escribiendo el disco: %v", err)
+	}
+	sessionUser := dropMounts(gone)
+
+	undo.Snapshots = undo.Snapshots[:len(undo.Snapshots)-1]
+	if err := writeUndoFile(diskPath, undo); err != nil {
+		return "", err
+	}
+
+	msg := fmt.Sprintf("FDISK: Se deshizo: %s (snapshot del %s, quedan %d)",
+		snapshot.Operation, snapshot.Time, len(undo.Snapshots))
+	if len(gone) > 0 {
+		msg += fmt.Sprintf("\n-> Se desmontaron: %s", strings.Join(gone, ", "))
+	}
+	if sessionUser != "" {
+		msg += fmt.Sprintf("\n-> Se cerró la sesión de '%s'", sessionUser)
+	}
+	return msg, nil
+}
+
+// mountedPartition partición montada con el ID que le asignó mount
+type mountedPartition struct {
+	id        string
+	partition structures.Partition
+}
+
+// mountedPartitions particiones montadas del disco tal como están antes de restaurar
+func mountedPartitions(diskPath string) []mountedPartition {
+	var mounted []mountedPartition
+	for _, id := range mountedIDsOnDisk(diskPath) {
+		if partition, _, err := stores.GetMountedPartition(id); err == nil {
+			mounted = append(mounted, mountedPartition{id: id, partition: *partition})
+		}
+	}
+	return mounted
+}
+
+// restoreMounts vuelve a marcar como montadas en la tabla restaurada las particiones que
+// lo estaban (el snapshot guarda el MBR y los EBR de antes del montaje). Devuelve los IDs
+// cuyas particiones ya no existen en la tabla restaurada.
+func restoreMounts(dev structures.Device, mounted []mountedPartition) ([]string, error) {
+	var mbr structures.MBR
+	if err := mbr.Deserialize(dev); err != nil {
+		return nil, err
+	}
+
+	var gone []string
+	for _, m := range mounted {
+		if position, ok := stores.MountedLogicals[m.id]; ok {
+			var ebr structures.EBR
+			if err := ebr.Deserialize(dev, int(position)); err != nil {
+				return nil, err
+			}
+			if ebr.IsEmpty() || ebr.Part_start != m.partition.Part_start {
+				gone = append(gone, m.id)
+				continue
+			}
+			ebr.Part_mount = [1]byte{'1'}
+			if err := ebr.Serialize(dev, int(position)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		found := false
+		for i := range mbr.Mbr_partitions {
+			partition := &mbr.Mbr_partitions[i]
+			if partition.Part_start == m.partition.Part_start && partition.Part_name == m.partition.Part_name {
+				partition.Part_status = m.partition.Part_status
+				partition.Part_correlative = m.partition.Part_correlative
+				partition.Part_id = m.partition.Part_id
+				found = true
+				break
+			}
+		}
+		if !found {
+			gone = append(gone, m.id)
+		}
+	}
+	return gone, mbr.Serialize(dev)
+}
+
+// removeUndoSnapshots borra el archivo de snapshots (al eliminar el disco)
+func removeUndoSnapshots(diskPath string) {
+	delete(memoryUndo, diskPath)
+	os.Remove(undoPath(diskPath))
+}
+
+func loadUndoFile(diskPath string) (*undoFile, error) {
+	undo := &undoFile{}
+	if stores.IsMemDevice(diskPath) {
+		if saved, ok := memoryUndo[diskPath]; ok {
+			undo.Snapshots = append(undo.Snapshots, saved.Snapshots...)
+		}
+		return undo, nil
+	}
+
+	data, err := os.ReadFile(undoPath(diskPath))
+	if os.IsNotExist(err) {
+		return undo, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ERROR: no se pudo leer %s: %v", undoPath(diskPath), err)
+	}
+	if err := json.Unmarshal(data, undo); err != nil {
+		return nil, fmt.Errorf("ERROR: el archivo %s está dañado: %v", undoPath(diskPath), err)
+	}
+	return undo, nil
+}
+
+func writeUndoFile(diskPath string, undo *undoFile) error {
+	if len(undo.Snapshots) == 0 {
+		removeUndoSnapshots(diskPath)
+		return nil
+	}
+	if stores.IsMemDevice(diskPath) {
+		memoryUndo[diskPath] = undo
+		return nil
+	}
+
+	data, err := json.MarshalIndent(undo, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ERROR: error serializando los snapshots: %v", err)
+	}
+
+	// Escribir en un temporal y renombrar para no dejar el archivo a medias
+	tmp := undoPath(diskPath) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("ERROR: error guardando los snapshots: %v", err)
+	}
+	return os.Rename(tmp, undoPath(diskPath))
+}
+
+// hashRegion calcula el SHA-256 de [start, start+size). Si la zona empieza con un
+// superbloque se ignoran sus tiempos y su contador de montajes, que mount y unmount
+// actualizan sin tocar los datos.
+func hashRegion(r io.ReaderAt, name string, start, size int64) (regionHash, error) {
+	h := sha256.New()
+	skip := int64(0)
+
+	var sb structures.SuperBlock
+	if size >= structures.SuperBlockSize {
+		header := io.NewSectionReader(r, start, structures.SuperBlockSize)
+		if err := binary.Read(header, binary.LittleEndian, &sb); err == nil && sb.S_magic == 0xEF53 {
+			sb.S_mtime, sb.S_umtime, sb.S_mnt_count = 0, 0, 0
+			if err := binary.Write(h, binary.LittleEndian, &sb); err != nil {
+				return regionHash{}, err
+			}
+			skip = structures.SuperBlockSize
+		}
+	}
+
+	if _, err := io.Copy(h, io.NewSectionReader(r, start+skip, size-skip)); err != nil {
+		return regionHash{}, err
+	}
+	return regionHash{Name: name, Start: start, Size: size, Hash: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// ebrChainPositions posiciones de todos los EBR de la cadena, incluido el primero aunque esté vacío
+func ebrChainPositions(dev structures.Device, extStart int64) []int64 {
+	var positions []int64
+	position := extStart
+	ebrSize := int64(binary.Size(structures.EBR{}))
+
+	for i := 0; i < 1024 && position >= 0; i++ {
+		ebr := structures.EBR{}
+		if err := ebr.Deserialize(dev, int(position)); err != nil {
+			break
+		}
+		positions = append(positions, position)
+		if ebr.Part_next == -1 || int64(ebr.Part_next) < position+ebrSize {
+			break
+		}
+		position = int64(ebr.Part_next)
+	}
+	return positions
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	stores "backend/stores"
+	structures "backend/structures"
+	utils "backend/utils"
+)
+
+/*
+	layout -id=391A
+*/
+
+// ParseLayout muestra la distribución del sistema de archivos de una partición montada
+func ParseLayout(params utils.Params) (string, error) {
+	id := params.Value("id")
+
+	diskPath, partStart, partSize, err := resolveMkfsTarget(id)
+	if err != nil {
+		return "", fmt.Errorf("LAYOUT ERROR: %v", err)
+	}
+
+	dev, err := stores.OpenDevice(diskPath)
+	if err != nil {
+		return "", fmt.Errorf("LAYOUT ERROR: error abriendo el disco: %v", err)
+	}
+
+	sb := structures.SuperBlock{}
+	if err := sb.Deserialize(dev, partStart); err != nil || sb.S_magic != 0xEF53 {
+		return "", fmt.Errorf("LAYOUT ERROR: la partición %s no tiene un sistema de archivos EXT2/EXT3", id)
+	}
+
+	layout, err := sb.Layout(partStart, partSize)
+	if err != nil {
+		return "", fmt.Errorf("LAYOUT ERROR: %v", err)
+	}
+
+	fsName, bitmaps := "EXT2", "de un byte"
+	if layout.Ext3 {
+		fsName = "EXT3"
+	}
+	if layout.Packed {
+		bitmaps = "de un bit"
+	}
+
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("LAYOUT: %s de %s, bitmaps %s\n", fsName, id, bitmaps))
+	msg.WriteString(fmt.Sprintf("-> Partición: %d - %d (%d bytes)", partStart, partStart+partSize, partSize))
+	for _, region := range layout.Regions() {
+		msg.WriteString(fmt.Sprintf("\n-> %s: %d - %d (%d bytes)", region.Name, region.Start, region.End, region.End-region.Start))
+	}
+	if !layout.Fits() {
+		msg.WriteString(fmt.Sprintf("\n-> AVISO: los bloques terminan %d bytes después del final de la partición", layout.End-partStart-partSize))
+	}
+	return msg.String(), nil
+}
@@ -2,9 +2,8 @@ package commands
 
 import (
 	stores "backend/stores"
-	"errors"
+	utils "backend/utils"
 	"fmt"
-	"regexp"
 	"strings"
 )
 
@@ -19,78 +18,12 @@ type LOGIN struct {
 	login -user=root -pass=123 -id=062A3E2D
 */
 
-func ParseLogin(tokens []string) (string, error) {
+func ParseLogin(params utils.Params) (string, error) {
 	cmd := &LOGIN{} // Crea una nueva instancia de LOGIN
 
-	// Unir tokens en una sola cadena y luego dividir por espacios, respetando las comillas
-	args := strings.Join(tokens, " ")
-	// Expresión regular para encontrar los parámetros del comando mkfs
-	re := regexp.MustCompile(`-user=[^\s]+|-pass=[^\s]+|-id=[^\s]+`)
-	// Encuentra todas las coincidencias de la expresión regular en la cadena de argumentos
-	matches := re.FindAllString(args, -1)
-
-	// Verificar que todos los tokens fueron reconocidos por la expresión regular
-	if len(matches) != len(tokens) {
-		// Identificar el parámetro inválido
-		for _, token := range tokens {
-			if !re.MatchString(token) {
-				return "", fmt.Errorf("parámetro inválido: %s", token)
-			}
-		}
-	}
-
-	// Itera sobre cada coincidencia encontrada
-	for _, match := range matches {
-		// Divide cada parte en clave y valor usando "=" como delimitador
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parámetro inválido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
-
-		// Remove quotes from value if present
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
-
-		// Switch para manejar diferentes parámetros
-		switch key {
-		case "-user":
-			if value == "" {
-				return "", errors.New("el usuario no puede estar vacío")
-			}
-			cmd.user = value
-		case "-pass":
-			if value == "" {
-				return "", errors.New("la contraseña no puede estar vacía")
-			}
-			cmd.pass = value
-		case "-id":
-			// Verifica que el id no esté vacío
-			if value == "" {
-				return "", errors.New("el id no puede estar vacío")
-			}
-			cmd.id = value
-		default:
-			// Si el parámetro no es reconocido, devuelve un error
-			return "", fmt.Errorf("parámetro desconocido: %s", key)
-		}
-	}
-
-	// Verifica que el parámetro -id haya sido proporcionado
-	if cmd.id == "" {
-		return "", errors.New("faltan parámetros requeridos: -id")
-	}
-
-	// Si no se proporcionó el tipo, se establece por defecto a "full"
-	if cmd.user == "" {
-		return "", errors.New("faltan parámetros requeridos: -user")
-	}
-
-	// Si no se proporcionó el tipo, se establece por defecto a "full"
-	if cmd.pass == "" {
-		return "", errors.New("faltan parámetros requeridos: -pass")
-	}
+	cmd.user = params.Value("user")
+	cmd.pass = params.Value("pass")
+	cmd.id = params.Value("id")
 
 	// Aquí se puede agregar la lógica para ejecutar el comando mkfs con los parámetros proporcionados
 	err := commandLogin(cmd)
@@ -98,13 +31,13 @@ func ParseLogin(tokens []string) (string, error) {
 		return "", err
 	}
 
-	return fmt.Sprintf("LOGIN: Usuario: %s, Contraseña: %s, ID: %s", cmd.user, cmd.pass, cmd.id), nil
+	return fmt.Sprintf("LOGIN: Usuario: %s, ID: %s", cmd.user, cmd.id), nil
 }
 
 func commandLogin(login *LOGIN) error {
 	// Verificar si ya hay una sesión iniciada
 	if stores.Auth.IsAuthenticated() {
-		currentUser, _, currentPartitionID := stores.Auth.GetCurrentUser()
+		currentUser, currentPartitionID := stores.Auth.GetCurrentUser()
 
 		if currentUser == login.user && currentPartitionID == login.id {
 			return fmt.Errorf("ya has iniciado sesión como %s en la partición %s", login.user, login.id)
@@ -112,36 +45,39 @@ func commandLogin(login *LOGIN) error {
 	}
 
 	// Obtener la partición montada
-	partitionSuperblock, _, partitionPath, err := stores.GetMountedPartitionSuperblock(login.id)
+	partitionSuperblock, partition, partitionPath, err := stores.GetMountedPartitionSuperblock(login.id)
 	if err != nil {
 		return fmt.Errorf("error al obtener la partición montada: %w", err)
 	}
-
-	// Obtener el bloque de usuarios
-	usersBlock, err := partitionSuperblock.GetUsersBlock(partitionPath)
+	dev, err := stores.OpenDevice(partitionPath)
 	if err != nil {
-		return fmt.Errorf("error al obtener el bloque de usuarios: %w", err)
+		return fmt.Errorf("error al abrir el disco: %w", err)
 	}
 
-	// Convertir el contenido del bloque a string y quitar padding \x00
-	content := strings.ReplaceAll(string(usersBlock.B_content[:]), "\x00", "")
+	// Leer el contenido completo de users.txt (puede ocupar varios bloques)
+	content, err := readUsersFileMkusr(partitionSuperblock, dev)
+	if err != nil {
+		return fmt.Errorf("error al leer users.txt: %w", err)
+	}
 	lines := strings.Split(content, "\n")
 
 	var foundUser bool
 	var userPassword string
+	userLine := -1
 
 	// Buscar el usuario
-	for _, line := range lines {
+	for i, line := range lines {
 		fields := strings.Split(line, ",")
-		for i := range fields {
-			fields[i] = strings.TrimSpace(fields[i])
+		for j := range fields {
+			fields[j] = strings.TrimSpace(fields[j])
 		}
 
-		if len(fields) >= 5 && fields[1] == "U" {
+		if len(fields) >= 5 && fields[1] == "U" && fields[0] != "0" {
 			// El nombre de usuario está en fields[3] y la contraseña en fields[4]
 			if fields[3] == login.user {
 				foundUser = true
 				userPassword = fields[4]
+				userLine = i
 				break
 			}
 		}
@@ -151,12 +87,30 @@ func commandLogin(login *LOGIN) error {
 		return fmt.Errorf("el usuario %s no existe", login.user)
 	}
 
-	if userPassword != login.pass {
+	ok, needsUpgrade := utils.VerifyPassword(userPassword, login.pass)
+	if !ok {
 		return fmt.Errorf("la contraseña no coincide")
 	}
 
+	// Las entradas en texto plano se reemplazan por su hash tras un login exitoso
+	if needsUpgrade {
+		hashed, err := utils.HashPassword(login.pass)
+		if err != nil {
+			return fmt.Errorf("error al generar el hash de la contraseña: %w", err)
+		}
+
+		fields := strings.Split(lines[userLine], ",")
+		fields[4] = hashed
+		lines[userLine] = strings.Join(fields, ",")
+
+		err = writeUsersFileMkusr(partitionSuperblock, partition, dev, strings.Join(lines, "\n"))
+		if err != nil {
+			return fmt.Errorf("error al actualizar la contraseña en users.txt: %w", err)
+		}
+	}
+
 	// Guardar estado de autenticación
-	stores.Auth.Login(login.user, login.pass, login.id)
+	stores.Auth.Login(login.user, login.id)
 
 	return nil
 }
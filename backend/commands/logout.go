@@ -2,6 +2,7 @@ package commands
 
 import (
 	stores "backend/stores"
+	utils "backend/utils"
 	"errors"
 	"fmt"
 )
@@ -13,12 +14,7 @@ type LOGOUT struct{}
 	logout
 */
 
-func ParseLogout(tokens []string) (string, error) {
-	// Verificar que no haya parámetros adicionales
-	if len(tokens) > 0 {
-		return "", fmt.Errorf("el comando logout no acepta parámetros")
-	}
-
+func ParseLogout(params utils.Params) (string, error) {
 	cmd := &LOGOUT{} // Crea una nueva instancia de LOGOUT
 
 	// Ejecutar el comando logout
@@ -37,7 +33,7 @@ func commandLogout(logout *LOGOUT) error {
 	}
 
 	// Obtener la información de la sesión actual antes de cerrarla
-	username, _, partitionID := stores.Auth.GetCurrentUser()
+	username, partitionID := stores.Auth.GetCurrentUser()
 
 	// Cerrar la sesión
 	stores.Auth.Logout()
@@ -0,0 +1,374 @@
+package commands
+
+import (
+	stores "backend/stores"
+	structures "backend/structures"
+	utils "backend/utils"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+/*
+	migrate -path=/home/user/Disco1.mia
+	migrate -path=/home/user/Disco1.mia -dryrun
+*/
+
+// Crecimiento de cada estructura al pasar de v1 a v2
+var (
+	mbrGrowth        = int64(binary.Size(structures.MBR{}) - binary.Size(structures.MBRV1{}))
+	ebrGrowth        = int64(binary.Size(structures.EBR{}) - binary.Size(structures.EBRV1{}))
+	superblockGrowth = int64(binary.Size(structures.SuperBlock{}) - binary.Size(structures.SuperBlockV1{}))
+)
+
+// Inodos que se convierten por lectura al migrar la tabla de inodos
+const migrateInodeChunk = 512
+
+// diskMigration convierte un disco v1 escribiendo la imagen v2 en otro archivo. Como
+// el MBR, los EBR y los superbloques crecen, todo lo que está después se desplaza:
+// shift es el desplazamiento acumulado hasta la posición que se está copiando.
+type diskMigration struct {
+	src     *os.File
+	dst     *os.File
+	shift   int64
+	changes []string
+	dropped int // Entradas del journal que ya no caben en el área reservada
+}
+
+// ParseMigrate convierte un disco del formato v1 al formato actual
+func ParseMigrate(params utils.Params) (string, error) {
+	path := params.Value("path")
+	dryRun := params.Has("dryrun")
+
+	// Los discos en memoria siempre se crean en el formato actual
+	if stores.IsMemDevice(path) {
+		return fmt.Sprintf("MIGRATE: el disco %s ya está en el formato v%d", path, structures.FormatVersion), nil
+	}
+
+	// El disco se lee y se reemplaza por fuera del caché: se guarda y se cierra antes
+	if err := stores.CloseDevice(path); err != nil {
+		return "", fmt.Errorf("MIGRATE ERROR: no se pudo cerrar el disco: %v", err)
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("MIGRATE ERROR: no se pudo abrir el disco: %v", err)
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return "", fmt.Errorf("MIGRATE ERROR: %v", err)
+	}
+
+	var magic [4]byte
+	if _, err := src.ReadAt(magic[:], 0); err == nil && string(magic[:]) == structures.DiskMagic {
+		return fmt.Sprintf("MIGRATE: el disco %s ya está en el formato v%d", path, structures.FormatVersion), nil
+	}
+
+	// El formato v2 mueve el inicio de las particiones y los EBR: los montajes quedarían
+	// apuntando a posiciones viejas
+	mounted := mountedIDsOnDisk(path)
+	if len(mounted) > 0 && !dryRun {
+		return "", fmt.Errorf("MIGRATE ERROR: el disco tiene particiones montadas (%s); desmóntelas primero", strings.Join(mounted, ", "))
+	}
+
+	var old structures.MBRV1
+	if err := structures.ReadV1(src, 0, &old); err != nil {
+		return "", fmt.Errorf("MIGRATE ERROR: no se pudo leer el MBR: %v", err)
+	}
+	if int64(old.Mbr_size) != info.Size() {
+		return "", fmt.Errorf("MIGRATE ERROR: %s no es un disco v1 válido (Mbr_size %d, archivo de %d bytes)", path, old.Mbr_size, info.Size())
+	}
+
+	// La imagen nueva se arma en un temporal y reemplaza al disco solo si todo salió bien
+	tmpPath := path + ".migrate"
+	dst, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", fmt.Errorf("MIGRATE ERROR: no se pudo crear %s: %v", tmpPath, err)
+	}
+	defer os.Remove(tmpPath)
+	defer dst.Close()
+
+	migration := &diskMigration{src: src, dst: dst}
+	mbr, err := migration.run(&old, info.Size())
+	if err != nil {
+		return "", fmt.Errorf("MIGRATE ERROR: %v", err)
+	}
+
+	var msg strings.Builder
+	if dryRun {
+		msg.WriteString("MIGRATE [dry-run]: el disco se convertiría al formato v2, no se escribió nada")
+	} else {
+		if err := dst.Sync(); err != nil {
+			return "", fmt.Errorf("MIGRATE ERROR: %v", err)
+		}
+		if err := os.Rename(tmpPath, path); err != nil {
+			return "", fmt.Errorf("MIGRATE ERROR: no se pudo reemplazar el disco: %v", err)
+		}
+		// Los snapshots de fdisk guardan bytes del formato v1 y ya no se pueden aplicar
+		_ = os.Remove(undoPath(path))
+		msg.WriteString(fmt.Sprintf("MIGRATE: Disco convertido al formato v%d -> Path: %s", structures.FormatVersion, path))
+	}
+
+	msg.WriteString(fmt.Sprintf("\n-> Tamaño: %d -> %d bytes", old.Mbr_size, mbr.Mbr_size))
+	for _, change := range migration.changes {
+		msg.WriteString("\n-> " + change)
+	}
+	if migration.dropped > 0 {
+		msg.WriteString(fmt.Sprintf("\n-> Se descartaron %d entradas del journal que ya no caben en su área", migration.dropped))
+	}
+	if len(mounted) > 0 {
+		msg.WriteString(fmt.Sprintf("\n-> Se rechazaría: hay particiones montadas (%s)", strings.Join(mounted, ", ")))
+	}
+	return msg.String(), nil
+}
+
+// run copia el disco al formato v2 y devuelve el MBR nuevo
+func (m *diskMigration) run(old *structures.MBRV1, diskSize int64) (*structures.MBR, error) {
+	mbr := old.ToV2()
+
+	// Las particiones se recorren en el orden en que están en el disco
+	var order []int
+	for i, partition := range old.Mbr_partitions {
+		if partition.Part_start != -1 && partition.Part_size > 0 {
+			order = append(order, i)
+		}
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return old.Mbr_partitions[order[a]].Part_start < old.Mbr_partitions[order[b]].Part_start
+	})
+
+	cursor := int64(binary.Size(old))
+	m.shift = mbrGrowth
+	for _, i := range order {
+		partition := &old.Mbr_partitions[i]
+		start, size := int64(partition.Part_start), int64(partition.Part_size)
+		name := strings.TrimRight(string(partition.Part_name[:]), "\x00")
+		if err := m.copyRaw(cursor, start-cursor); err != nil {
+			return nil, err
+		}
+
+		newStart := start + m.shift
+		var newSize int64
+		var err error
+		if partition.Part_type[0] == 'E' {
+			newSize, err = m.migrateExtended(start, size)
+		} else {
+			newSize, err = m.migratePartition(name, start, size)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("partición '%s': %v", name, err)
+		}
+
+		mbr.Mbr_partitions[i].Part_start = newStart
+		mbr.Mbr_partitions[i].Part_size = newSize
+		cursor = start + size
+	}
+	if err := m.copyRaw(cursor, diskSize-cursor); err != nil {
+		return nil, err
+	}
+
+	mbr.Mbr_size = diskSize + m.shift
+	if err := m.dst.Truncate(mbr.Mbr_size); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(io.NewOffsetWriter(m.dst, 0), binary.LittleEndian, &mbr); err != nil {
+		return nil, err
+	}
+	return &mbr, nil
+}
+
+// migrateExtended convierte la cadena de EBR de la extendida y sus lógicas
+func (m *diskMigration) migrateExtended(extStart, extSize int64) (int64, error) {
+	startShift := m.shift
+	cursor := extStart
+	ebrSize := int64(binary.Size(structures.EBRV1{}))
+
+	for position := extStart; position != -1; {
+		if position < cursor || position+ebrSize > extStart+extSize {
+			return 0, fmt.Errorf("cadena de EBR inválida en el byte %d", position)
+		}
+		var old structures.EBRV1
+		if err := structures.ReadV1(m.src, position, &old); err != nil {
+			return 0, fmt.Errorf("error leyendo EBR: %v", err)
+		}
+		if err := m.copyRaw(cursor, position-cursor); err != nil {
+			return 0, err
+		}
+
+		newPosition := position + m.shift
+		m.shift += ebrGrowth
+		cursor = position + ebrSize
+
+		ebr := old.ToV2()
+		if old.Part_size > 0 {
+			start := int64(old.Part_start)
+			name := strings.TrimRight(string(old.Part_name[:]), "\x00")
+			if start < cursor {
+				return 0, fmt.Errorf("la lógica '%s' se solapa con su EBR", name)
+			}
+			if err := m.copyRaw(cursor, start-cursor); err != nil {
+				return 0, err
+			}
+			ebr.Part_start = start + m.shift
+			newSize, err := m.migratePartition(name, start, int64(old.Part_size))
+			if err != nil {
+				return 0, fmt.Errorf("lógica '%s': %v", name, err)
+			}
+			ebr.Part_size = newSize
+			cursor = start + int64(old.Part_size)
+		}
+
+		// El siguiente EBR se desplaza lo acumulado hasta aquí
+		position = int64(old.Part_next)
+		if position != -1 {
+			ebr.Part_next = position + m.shift
+		}
+		if err := binary.Write(io.NewOffsetWriter(m.dst, newPosition), binary.LittleEndian, &ebr); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := m.copyRaw(cursor, extStart+extSize-cursor); err != nil {
+		return 0, err
+	}
+	return extSize + m.shift - startShift, nil
+}
+
+// migratePartition convierte el sistema de archivos de una partición (o la copia tal cual
+// si no está formateada) y devuelve su tamaño nuevo
+func (m *diskMigration) migratePartition(name string, start, size int64) (int64, error) {
+	newStart := start + m.shift
+
+	var old structures.SuperBlockV1
+	if err := structures.ReadV1(m.src, start, &old); err != nil || old.S_magic != 0xEF53 {
+		m.changes = append(m.changes, fmt.Sprintf("Partición '%s': inicio %d -> %d, sin sistema de archivos", name, start, newStart))
+		return size, m.copyRaw(start, size)
+	}
+
+	// Los offsets del superbloque son absolutos: se mueven con la partición y con el
+	// superbloque, que ahora es más grande
+	offset := newStart - start + superblockGrowth
+	sb := old.ToV2()
+	sb.S_first_ino += offset
+	sb.S_first_blo += offset
+	sb.S_bm_inode_start += offset
+	sb.S_bm_block_start += offset
+	sb.S_inode_start += offset
+	sb.S_block_start += offset
+	if err := binary.Write(io.NewOffsetWriter(m.dst, newStart), binary.LittleEndian, &sb); err != nil {
+		return 0, err
+	}
+
+	fsName := "EXT2"
+	if old.S_filesystem_type == 3 {
+		fsName = "EXT3"
+		// El área del journal conserva su tamaño: va del superbloque al bitmap de inodos
+		oldJournal := start + int64(binary.Size(old))
+		if err := m.migrateJournal(oldJournal, oldJournal+offset, int64(old.S_bm_inode_start)-oldJournal); err != nil {
+			return 0, err
+		}
+	}
+
+	// Los bitmaps y los bloques guardan índices, así que se copian sin cambios
+	bitmaps := int64(old.S_inode_start - old.S_bm_inode_start)
+	if err := m.copyTo(int64(old.S_bm_inode_start), sb.S_bm_inode_start, bitmaps); err != nil {
+		return 0, err
+	}
+	if err := m.migrateInodes(&old, &sb); err != nil {
+		return 0, err
+	}
+	if err := m.copyTo(int64(old.S_block_start), sb.S_block_start, start+size-int64(old.S_block_start)); err != nil {
+		return 0, err
+	}
+
+	m.changes = append(m.changes, fmt.Sprintf("Partición '%s': inicio %d -> %d, tamaño %d -> %d (%s, %d inodos)",
+		name, start, newStart, size, size+superblockGrowth, fsName, sb.S_inodes_count))
+	m.shift += superblockGrowth
+	return size + superblockGrowth, nil
+}
+
+// migrateJournal convierte las entradas del journal que siguen cabiendo en el área
+// reservada de journalSize bytes
+func (m *diskMigration) migrateJournal(oldStart, newStart, journalSize int64) error {
+	oldSize := int64(binary.Size(structures.JournalV1{}))
+	newSize := structures.JournalEntrySize
+	if err := utils.ZeroFill(m.dst, newStart, journalSize); err != nil {
+		return err
+	}
+
+	for i := int64(0); (i+1)*oldSize <= journalSize; i++ {
+		var old structures.JournalV1
+		if err := structures.ReadV1(m.src, oldStart+i*oldSize, &old); err != nil {
+			return err
+		}
+		if old == (structures.JournalV1{}) {
+			continue
+		}
+		if (i+1)*newSize > journalSize {
+			m.dropped++
+			continue
+		}
+		journal := old.ToV2()
+		if err := binary.Write(io.NewOffsetWriter(m.dst, newStart+i*newSize), binary.LittleEndian, &journal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateInodes convierte la tabla de inodos; cada inodo sigue ocupando S_inode_size bytes
+func (m *diskMigration) migrateInodes(old *structures.SuperBlockV1, sb *structures.SuperBlock) error {
+	slot := int64(old.S_inode_size)
+	if slot < int64(binary.Size(structures.Inode{})) {
+		return fmt.Errorf("S_inode_size %d es menor que un inodo v2", slot)
+	}
+	count := int64(old.S_block_start-old.S_inode_start) / slot
+
+	buffer := make([]byte, migrateInodeChunk*slot)
+	for first := int64(0); first < count; first += migrateInodeChunk {
+		n := min(migrateInodeChunk, count-first)
+		chunk := buffer[:n*slot]
+		if _, err := m.src.ReadAt(chunk, int64(old.S_inode_start)+first*slot); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		converted := make([]byte, len(chunk))
+		for i := int64(0); i < n; i++ {
+			var inode structures.InodeV1
+			if err := structures.ReadV1(bytes.NewReader(chunk), i*slot, &inode); err != nil {
+				return err
+			}
+			if inode == (structures.InodeV1{}) {
+				continue
+			}
+			v2 := inode.ToV2()
+			if _, err := binary.Encode(converted[i*slot:], binary.LittleEndian, &v2); err != nil {
+				return err
+			}
+		}
+		if _, err := m.dst.WriteAt(converted, sb.S_inode_start+first*slot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyRaw copia length bytes de la imagen vieja a la nueva con el desplazamiento actual
+func (m *diskMigration) copyRaw(offset, length int64) error {
+	return m.copyTo(offset, offset+m.shift, length)
+}
+
+func (m *diskMigration) copyTo(src, dst, length int64) error {
+	if length <= 0 {
+		return nil
+	}
+	_, err := io.Copy(io.NewOffsetWriter(m.dst, dst), io.NewSectionReader(m.src, src, length))
+	return err
+}
@@ -6,8 +6,6 @@ import (
 	utils "backend/utils"
 	"errors"
 	"fmt"
-	"regexp"
-	"strings"
 )
 
 // MKDIR estructura que representa el comando mkdir con sus parámetros
@@ -21,56 +19,11 @@ type MKDIR struct {
    mkdir -path="/home/mis documentos/archivos clases"
 */
 
-func ParseMkdir(tokens []string) (string, error) {
+func ParseMkdir(params utils.Params) (string, error) {
 	cmd := &MKDIR{} // Crea una nueva instancia de MKDIR
 
-	// Unir tokens en una sola cadena y luego dividir por espacios, respetando las comillas
-	args := strings.Join(tokens, " ")
-	// Expresión regular para encontrar los parámetros del comando mkdir
-	re := regexp.MustCompile(`-path=[^\s]+|-p`)
-	// Encuentra todas las coincidencias de la expresión regular en la cadena de argumentos
-	matches := re.FindAllString(args, -1)
-
-	// Verificar que todos los tokens fueron reconocidos por la expresión regular
-	if len(matches) != len(tokens) {
-		// Identificar el parámetro inválido
-		for _, token := range tokens {
-			if !re.MatchString(token) {
-				return "", fmt.Errorf("parámetro inválido: %s", token)
-			}
-		}
-	}
-
-	// Itera sobre cada coincidencia encontrada
-	for _, match := range matches {
-		// Divide cada parte en clave y valor usando "=" como delimitador
-		kv := strings.SplitN(match, "=", 2)
-		key := strings.ToLower(kv[0])
-
-		// Switch para manejar diferentes parámetros
-		switch key {
-		case "-path":
-			if len(kv) != 2 {
-				return "", fmt.Errorf("formato de parámetro inválido: %s", match)
-			}
-			value := kv[1]
-			// Remove quotes from value if present
-			if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-				value = strings.Trim(value, "\"")
-			}
-			cmd.path = value
-		case "-p":
-			cmd.p = true
-		default:
-			// Si el parámetro no es reconocido, devuelve un error
-			return "", fmt.Errorf("parámetro desconocido: %s", key)
-		}
-	}
-
-	// Verifica que el parámetro -path haya sido proporcionado
-	if cmd.path == "" {
-		return "", errors.New("faltan parámetros requeridos: -path")
-	}
+	cmd.path = params.Value("path")
+	cmd.p = params.Has("p")
 
 	// Aquí se puede agregar la lógica para ejecutar el comando mkdir con los parámetros proporcionados
 	err := commandMkdir(cmd)
@@ -108,6 +61,11 @@ func commandMkdir(mkdir *MKDIR) error {
 }
 
 func createDirectory(dirPath string, sb *structures.SuperBlock, partitionPath string, mountedPartition *structures.Partition) error {
+	dev, err := stores.OpenDevice(partitionPath)
+	if err != nil {
+		return err
+	}
+
 	fmt.Println("\nCreando directorio:", dirPath)
 
 	parentDirs, destDir := utils.GetParentDirectories(dirPath)
@@ -115,17 +73,17 @@ func createDirectory(dirPath string, sb *structures.SuperBlock, partitionPath st
 	fmt.Println("Directorio destino:", destDir)
 
 	// Crear el directorio segun el path proporcionado
-	err := sb.CreateFolder(partitionPath, parentDirs, destDir)
+	err = sb.CreateFolder(dev, parentDirs, destDir)
 	if err != nil {
 		return fmt.Errorf("error al crear el directorio: %w", err)
 	}
 
 	// Imprimir inodos y bloques
-	sb.PrintInodes(partitionPath)
-	sb.PrintBlocks(partitionPath)
+	sb.PrintInodes(dev)
+	sb.PrintBlocks(dev)
 
 	// Serializar el superbloque
-	err = sb.Serialize(partitionPath, int64(mountedPartition.Part_start))
+	err = sb.Serialize(dev, mountedPartition.Part_start)
 	if err != nil {
 		return fmt.Errorf("error al serializar el superbloque: %w", err)
 	}
@@ -1,108 +1,48 @@
 package commands
 
 import (
+	stores "backend/stores"
 	structures "backend/structures"
+	utils "backend/utils"
 	"errors"
 	"fmt"
 	"math/rand"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
 )
 
 type MKDISK struct {
-	size int
-	unit string
-	fit  string
-	path string
+	size  int
+	unit  string
+	fit   string
+	path  string
+	alloc string // Reserva del espacio: full (se escriben ceros) o sparse (solo se fija el tamaño)
+	mem   bool   // El disco vive solo en memoria y se pierde al terminar el proceso
 }
 
-func ParseMkdisk(tokens []string) (string, error) {
+func ParseMkdisk(params utils.Params) (string, error) {
 	cmd := &MKDISK{}
 
-	args := strings.Join(tokens, " ")
-
-	// Regex para -clave=valor
-	re := regexp.MustCompile(`-(\w+)=(".*?"|\S+)`)
-	matches := re.FindAllStringSubmatch(args, -1)
-
-	if len(matches) == 0 {
-		return "", errors.New("ERROR: no se detectaron parámetros válidos para mkdisk")
+	// Los parámetros ya fueron validados contra la declaración del comando
+	size, _ := strconv.Atoi(params.Value("size"))
+	if size <= 0 {
+		return "", errors.New("ERROR: el tamaño debe ser un número entero positivo mayor que cero")
 	}
-
-	allowed := map[string]bool{
-		"-size": true,
-		"-unit": true,
-		"-fit":  true,
-		"-path": true,
+	cmd.size = size
+	cmd.unit = params.Value("unit")
+	cmd.fit = params.Value("fit")
+	cmd.alloc = params.Value("alloc")
+	cmd.mem = params.Has("mem")
+	if cmd.mem {
+		cmd.alloc = "memoria"
 	}
 
-	// Validar si hay parámetros desconocidos
-	for _, m := range matches {
-		key := "-" + strings.ToLower(m[1])
-		if _, ok := allowed[key]; !ok {
-			return "", fmt.Errorf("ERROR: parámetro desconocido: %s", key)
-		}
-	}
-
-	for _, m := range matches {
-		key := "-" + strings.ToLower(m[1])
-		value := m[2]
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") && len(value) >= 2 {
-			value = value[1 : len(value)-1]
-		}
-
-		if _, ok := allowed[key]; !ok {
-			return "", fmt.Errorf("ERROR: parámetro desconocido: %s", key)
-		}
-
-		switch key {
-		case "-size":
-			sz, err := strconv.Atoi(value)
-			if err != nil || sz <= 0 {
-				return "", errors.New("ERROR: el tamaño debe ser un número entero positivo mayor que cero")
-			}
-			cmd.size = sz
-		case "-unit":
-			valUp := strings.ToUpper(value)
-			if valUp != "K" && valUp != "M" {
-				return "", errors.New("ERROR: la unidad debe ser K o M")
-			}
-			cmd.unit = valUp
-		case "-fit":
-			valUp := strings.ToUpper(value)
-			if valUp != "BF" && valUp != "FF" && valUp != "WF" {
-				return "", errors.New("ERROR: el ajuste debe ser BF, FF o WF")
-			}
-			cmd.fit = valUp
-		case "-path":
-			if value == "" {
-				return "", errors.New("ERROR: el path no puede estar vacío")
-			}
-			if !filepath.IsAbs(value) {
-				return "", errors.New("ERROR: el path debe ser absoluto")
-			}
-			cmd.path = value
-		}
-	}
-
-	// Validación de parámetros obligatorios
-	if cmd.size == 0 {
-		return "", errors.New("ERROR: faltan parámetros requeridos: -size")
-	}
-	if cmd.path == "" {
-		return "", errors.New("ERROR: faltan parámetros requeridos: -path")
-	}
-
-	// Defaults
-	if cmd.unit == "" {
-		cmd.unit = "M"
-	}
-	if cmd.fit == "" {
-		cmd.fit = "FF"
+	cmd.path = params.Value("path")
+	if !filepath.IsAbs(cmd.path) {
+		return "", errors.New("ERROR: el path debe ser absoluto")
 	}
 
 	// Crear disco
@@ -110,8 +50,8 @@ func ParseMkdisk(tokens []string) (string, error) {
 		return "", err
 	}
 
-	return fmt.Sprintf("MKDISK: Disco creado exitosamente\n-> Path: %s\n-> Tamaño: %d%s\n-> Fit: %s\n",
-		cmd.path, cmd.size, cmd.unit, cmd.fit), nil
+	return fmt.Sprintf("MKDISK: Disco creado exitosamente\n-> Path: %s\n-> Tamaño: %d%s\n-> Fit: %s\n-> Asignación: %s\n",
+		cmd.path, cmd.size, cmd.unit, cmd.fit, cmd.alloc), nil
 }
 
 func commandMkdisk(mkdisk *MKDISK) error {
@@ -120,19 +60,30 @@ func commandMkdisk(mkdisk *MKDISK) error {
 		return err
 	}
 
-	if err := createDisk(mkdisk, sizeBytes); err != nil {
-		return err
+	var dev structures.Device
+	if mkdisk.mem {
+		if dev, err = stores.CreateMemDevice(mkdisk.path, sizeBytes); err != nil {
+			return fmt.Errorf("ERROR: %w", err)
+		}
+	} else {
+		if err := createDisk(mkdisk, sizeBytes); err != nil {
+			return err
+		}
+		if dev, err = stores.OpenDevice(mkdisk.path); err != nil {
+			_ = os.Remove(mkdisk.path)
+			return fmt.Errorf("ERROR: error abriendo el disco: %w", err)
+		}
 	}
 
-	if err := createMBR(mkdisk, sizeBytes); err != nil {
-		_ = os.Remove(mkdisk.path)
+	if err := createMBR(dev, mkdisk, sizeBytes); err != nil {
+		_ = stores.RemoveDisk(mkdisk.path)
 		return err
 	}
 
 	// *** MODIFICACIÓN AQUÍ ***
 	// Crear una nueva instancia de MBR para deserializar y luego imprimir
 	newMBR := &structures.MBR{}
-	if err := newMBR.Deserialize(mkdisk.path); err != nil {
+	if err := newMBR.Deserialize(dev); err != nil {
 		// Manejar el error de deserialización si ocurre
 		return fmt.Errorf("ERROR: no se pudo deserializar el MBR para imprimirlo: %w", err)
 	}
@@ -163,7 +114,7 @@ func createDisk(mkdisk *MKDISK, sizeBytes int64) error {
 		return fmt.Errorf("ERROR: error creando carpetas padres: %w", err)
 	}
 
-	if _, err := os.Stat(mkdisk.path); err == nil {
+	if _, err := os.Stat(mkdisk.path); err == nil || stores.IsMemDevice(mkdisk.path) {
 		return fmt.Errorf("ERROR: ya existe un disco en la ruta especificada: %s", mkdisk.path)
 	}
 
@@ -173,27 +124,22 @@ func createDisk(mkdisk *MKDISK, sizeBytes int64) error {
 	}
 	defer f.Close()
 
-	buf := make([]byte, 1024)
-	remaining := sizeBytes
-	for remaining > 0 {
-		toWrite := int64(len(buf))
-		if remaining < toWrite {
-			toWrite = remaining
-		}
-		n, err := f.Write(buf[:toWrite])
-		if err != nil {
-			return fmt.Errorf("ERROR: error escribiendo archivo: %w", err)
-		}
-		if int64(n) != toWrite {
-			return fmt.Errorf("ERROR: escritura incompleta")
-		}
-		remaining -= toWrite
+	// sparse solo fija el tamaño: el sistema operativo entrega ceros y reserva los
+	// bloques al escribirlos. full los escribe todos desde el inicio.
+	if mkdisk.alloc == "sparse" {
+		err = f.Truncate(sizeBytes)
+	} else {
+		err = utils.ZeroFill(f, 0, sizeBytes)
+	}
+	if err != nil {
+		_ = os.Remove(mkdisk.path)
+		return fmt.Errorf("ERROR: error escribiendo archivo: %w", err)
 	}
 
 	return f.Sync()
 }
 
-func createMBR(mkdisk *MKDISK, sizeBytes int64) error {
+func createMBR(dev structures.Device, mkdisk *MKDISK, sizeBytes int64) error {
 	var fitByte byte
 	switch mkdisk.fit {
 	case "FF":
@@ -204,27 +150,6 @@ func createMBR(mkdisk *MKDISK, sizeBytes int64) error {
 		fitByte = 'W'
 	}
 
-	mbr := &structures.MBR{
-		Mbr_size:           int32(sizeBytes),
-		Mbr_creation_date:  float32(time.Now().Unix()),
-		Mbr_disk_signature: rand.Int31(),
-		Mbr_disk_fit:       [1]byte{fitByte},
-		Mbr_partitions:     [4]structures.Partition{},
-	}
-
-	for i := 0; i < len(mbr.Mbr_partitions); i++ {
-		mbr.Mbr_partitions[i].Part_start = -1
-		mbr.Mbr_partitions[i].Part_size = 0
-		mbr.Mbr_partitions[i].Part_status = [1]byte{'0'}
-		mbr.Mbr_partitions[i].Part_type = [1]byte{'0'}
-		mbr.Mbr_partitions[i].Part_fit = [1]byte{'0'}
-		for j := range mbr.Mbr_partitions[i].Part_name {
-			mbr.Mbr_partitions[i].Part_name[j] = 0
-		}
-		for j := range mbr.Mbr_partitions[i].Part_id {
-			mbr.Mbr_partitions[i].Part_id[j] = 0
-		}
-	}
-
-	return mbr.Serialize(mkdisk.path)
+	mbr := structures.NewMBR(sizeBytes, time.Now().UnixNano(), rand.Int31(), fitByte)
+	return mbr.Serialize(dev)
 }
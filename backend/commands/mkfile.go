@@ -6,9 +6,7 @@ import (
 	utils "backend/utils"
 	"errors"
 	"fmt"
-	"regexp"
 	"strconv"
-	"strings"
 )
 
 type MKFILE struct {
@@ -18,36 +16,13 @@ type MKFILE struct {
 	cont string // Contenido del archivo
 }
 
-func ParserMkfile(tokens []string) (string, error) {
+func ParserMkfile(params utils.Params) (string, error) {
 	fmt.Println("LLEGUE A FILE?")
 	cmd := &MKFILE{}
 
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+|-r|-size=\d+|-cont="[^"]+"|-cont=[^\s]+`)
-	matches := re.FindAllString(args, -1)
-
-	// Verificar que todos los tokens fueron reconocidos por la expresión regular
-	if len(matches) != len(tokens) {
-		for _, token := range tokens {
-			if !re.MatchString(token) {
-				return "", fmt.Errorf("parámetro inválido: %s", token)
-			}
-		}
-	}
-
-	// Itera sobre cada coincidencia encontrada
-	for _, match := range matches {
-		// Divide cada parte en clave y valor usando "=" como delimitador
-		kv := strings.SplitN(match, "=", 2)
-		key := strings.ToLower(kv[0])
-		var value string
-		if len(kv) == 2 {
-			value = kv[1]
-		}
-
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+	// Itera sobre cada parámetro en el orden en que se escribió
+	for _, p := range params.List() {
+		key, value := "-"+p.Key, p.Value
 
 		switch key {
 		case "-path":
@@ -141,6 +116,11 @@ func generateContent(size int) string {
 
 // Funcion para crear un archivo
 func createFile(filePath string, size int, content string, sb *structures.SuperBlock, partitionPath string, mountedPartition *structures.Partition) error {
+	dev, err := stores.OpenDevice(partitionPath)
+	if err != nil {
+		return err
+	}
+
 	fmt.Println("\nCreando archivo:", filePath)
 
 	parentDirs, destDir := utils.GetParentDirectories(filePath)
@@ -152,17 +132,17 @@ func createFile(filePath string, size int, content string, sb *structures.SuperB
 	fmt.Println("\nChunks del contenido:", chunks)
 
 	// Crear el archivo
-	err := sb.CreateFile(partitionPath, parentDirs, destDir, size, chunks)
+	err = sb.CreateFile(dev, parentDirs, destDir, size, chunks)
 	if err != nil {
 		return fmt.Errorf("error al crear el archivo: %w", err)
 	}
 
 	// Imprimir inodos y bloques
-	sb.PrintInodes(partitionPath)
-	sb.PrintBlocks(partitionPath)
+	sb.PrintInodes(dev)
+	sb.PrintBlocks(dev)
 
 	// Serializar el superbloque
-	err = sb.Serialize(partitionPath, int64(mountedPartition.Part_start))
+	err = sb.Serialize(dev, mountedPartition.Part_start)
 	if err != nil {
 		return fmt.Errorf("error al serializar el superbloque: %w", err)
 	}
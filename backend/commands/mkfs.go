@@ -2,46 +2,27 @@ package commands
 
 import (
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
 	stores "backend/stores"
 	structures "backend/structures"
+	utils "backend/utils"
 )
 
 // ParseMkfs procesa el comando MKFS
-func ParseMkfs(tokens []string) (string, error) {
-	id := ""
-	ftype := "full" // valor por defecto
-	fs := "2fs"     // valor por defecto EXT2
-
-	for _, token := range tokens {
-		token = strings.TrimSpace(token)
-		lowerToken := strings.ToLower(token)
-
-		if strings.HasPrefix(lowerToken, "-id=") {
-			id = token[len("-id="):]
-		} else if strings.HasPrefix(lowerToken, "-type=") {
-			ftype = strings.ToLower(token[len("-type="):])
-			if ftype != "full" {
-				return "", fmt.Errorf("MKFS ERROR: tipo inválido '%s' (solo 'full')", ftype)
-			}
-		} else if strings.HasPrefix(lowerToken, "-fs=") {
-			fs = strings.ToLower(token[len("-fs="):])
-			if fs != "2fs" && fs != "3fs" {
-				return "", fmt.Errorf("MKFS ERROR: sistema de archivos inválido '%s' (solo '2fs' o '3fs')", fs)
-			}
-		} else if token != "" && token != "mkfs" {
-			return "", fmt.Errorf("MKFS ERROR: parámetro no reconocido '%s'", token)
-		}
-	}
-
-	if id == "" {
-		return "", fmt.Errorf("MKFS ERROR: el parámetro -id es obligatorio")
+func ParseMkfs(params utils.Params) (string, error) {
+	id := params.Value("id")
+	ftype := params.Value("type") // full por defecto, o fast
+	fs := params.Value("fs")      // 2fs (EXT2) por defecto
+	packed := params.Value("bitmap") == "bit"
+
+	if params.Has("dryrun") {
+		return planMkfs(id, ftype, fs, packed)
 	}
 
-	if err := Mkfs(id, ftype, fs); err != nil {
+	warning, err := Mkfs(id, ftype, fs, packed)
+	if err != nil {
 		return "", err
 	}
 
@@ -49,95 +30,46 @@ func ParseMkfs(tokens []string) (string, error) {
 	if fs == "3fs" {
 		fsType = "EXT3"
 	}
-	return fmt.Sprintf("MKFS: Formateo completado con éxito en %s", fsType), nil
+	msg := fmt.Sprintf("MKFS: Formateo %s completado con éxito en %s", ftype, fsType)
+	if warning != "" {
+		msg += "\n-> ADVERTENCIA: " + warning
+	}
+	return msg, nil
 }
 
-// Mkfs formatea una partición con EXT2 o EXT3
-func Mkfs(id string, ftype string, fs string) error {
+// Mkfs formatea una partición con EXT2 o EXT3. Con ftype full primero llena la partición
+// de ceros; con fast solo reescribe el superbloque, el journal, los bitmaps, la raíz y
+// users.txt, y los datos anteriores quedan en los bloques libres. Con packed los bitmaps usan un bit
+// por inodo o bloque (FeaturePackedBitmaps). Devuelve una advertencia si la partición
+// ya tenía un sistema de archivos.
+func Mkfs(id string, ftype string, fs string, packed bool) (string, error) {
 	// 1) Resolver id -> path/offset/size
-	pathEntry, ok := stores.MountedPartitions[id]
-	if !ok {
-		// Búsqueda case-insensitive
-		for k, v := range stores.MountedPartitions {
-			if strings.EqualFold(k, id) {
-				pathEntry = v
-				ok = true
-				break
-			}
-		}
-		if !ok {
-			return fmt.Errorf("partición %s no encontrada o no montada", id)
-		}
-	}
-
-	// Parsear pathEntry: formato esperado "path|start|size"
-	var diskPath string
-	var partStart int64
-	var partSize int64
-
-	if strings.Contains(pathEntry, "|") {
-		parts := strings.Split(pathEntry, "|")
-		diskPath = parts[0]
-		if len(parts) > 1 {
-			fmt.Sscan(parts[1], &partStart)
-		}
-		if len(parts) > 2 {
-			fmt.Sscan(parts[2], &partSize)
-		}
-	} else {
-		diskPath = pathEntry
-		// Necesitamos obtener start y size del MBR
-		mbr := &structures.MBR{}
-		if err := mbr.Deserialize(diskPath); err != nil {
-			return fmt.Errorf("error al leer MBR: %v", err)
-		}
-
-		// Buscar la partición por ID
-		partition, err := mbr.GetPartitionByID(id)
-		if err != nil {
-			return fmt.Errorf("partición no encontrada en MBR: %v", err)
-		}
-
-		partStart = int64(partition.Part_start)
-		partSize = int64(partition.Part_size)
+	diskPath, partStart, partSize, err := resolveMkfsTarget(id)
+	if err != nil {
+		return "", err
 	}
 
-	// 2) Abrir archivo disco
-	f, err := os.OpenFile(diskPath, os.O_RDWR, 0666)
+	// 2) Abrir el disco y avisar si se reemplaza un sistema de archivos
+	dev, err := stores.OpenDevice(diskPath)
 	if err != nil {
-		return fmt.Errorf("no se pudo abrir disco %s: %v", diskPath, err)
+		return "", fmt.Errorf("no se pudo abrir disco %s: %v", diskPath, err)
 	}
-	defer f.Close()
-
-	// 3) Calcular número de estructuras según el sistema de archivos
-	var n int64
-
-	if fs == "3fs" {
-		// EXT3: tamaño_particion = sizeof(superblock) + 50*sizeof(journal) + n + 3*n + n*sizeof(inodo) + 3*n*sizeof(block)
-		// sizeof(superblock) = 68 bytes
-		// sizeof(journal) = aprox 256 bytes (content + metadata)
-		// sizeof(inodo) = 128 bytes
-		// sizeof(block) = 64 bytes
-
-		journalSize := int64(50 * 256) // 50 journals de 256 bytes cada uno
-		superblockSize := int64(68)
 
-		// tamaño_particion = 68 + 12800 + n + 3n + 128n + 192n = 12868 + 324n
-		// n = (tamaño_particion - 12868) / 324
-		n = (partSize - superblockSize - journalSize) / (1 + 3 + 128 + 192)
-
-	} else {
-		// EXT2: tamaño_particion = sizeof(superblock) + n + 3*n + n*sizeof(inodo) + 3*n*sizeof(block)
-		// sizeof(superblock) = 68 bytes
-		// tamaño_particion = 68 + n + 3n + 128n + 192n = 68 + 324n
-		// n = (tamaño_particion - 68) / 324
-		n = (partSize - 68) / (1 + 3 + 128 + 192)
+	warning := existingFilesystemWarning(dev, id, partStart)
+	if warning != "" {
+		fmt.Println("ADVERTENCIA:", warning)
 	}
 
-	if n < 3 {
-		n = 3 // mínimo razonable
+	if ftype == "full" {
+		if err := utils.ZeroFill(dev, partStart, partSize); err != nil {
+			return "", fmt.Errorf("error al llenar de ceros la partición: %v", err)
+		}
 	}
 
+	// 3) Calcular la distribución según el sistema de archivos
+	layout := structures.NewLayout(partStart, partSize, fs == "3fs", packed)
+	n := layout.Inodes
+
 	// 4) Crear SuperBlock
 	sb := structures.SuperBlock{}
 
@@ -151,79 +83,38 @@ func Mkfs(id string, ftype string, fs string) error {
 	sb.S_blocks_count = int32(3 * n)
 	sb.S_free_inodes_count = int32(n - 2)   // Reservamos root y users.txt
 	sb.S_free_blocks_count = int32(3*n - 2) // Reservamos 2 bloques
-	sb.S_mtime = float32(time.Now().Unix())
+	sb.S_mtime = time.Now().UnixNano()
 	sb.S_umtime = 0
 	sb.S_mnt_count = 0
 	sb.S_magic = 0xEF53
-	sb.S_inode_size = 128
-	sb.S_block_size = 64
 
 	// IMPORTANTE: Los offsets en el superbloque son ABSOLUTOS (incluyen partStart)
-	// Calcular offsets absolutos desde el inicio del disco
-	currentOffset := int32(partStart)
-
-	// Superblock en el inicio de la partición
-	currentOffset += 68
-
-	// Si es EXT3, añadir espacio para journal
-	if fs == "3fs" {
-		currentOffset += int32(50 * 256) // 50 journals
-	}
-
-	sb.S_bm_inode_start = currentOffset
-	currentOffset += int32(n)
-
-	sb.S_bm_block_start = currentOffset
-	currentOffset += int32(3 * n)
-
-	sb.S_inode_start = currentOffset
-	sb.S_first_ino = currentOffset // Primer inodo libre (al principio son iguales)
-	currentOffset += int32(n * 128)
-
-	sb.S_block_start = currentOffset
-	sb.S_first_blo = currentOffset // Primer bloque libre (al principio son iguales)
+	layout.Apply(&sb)
+	sb.S_first_ino = sb.S_inode_start // Primer inodo libre (al principio son iguales)
+	sb.S_first_blo = sb.S_block_start // Primer bloque libre (al principio son iguales)
 
 	// 5) Escribir Superblock
-	if err := sb.Serialize(diskPath, partStart); err != nil {
-		return fmt.Errorf("error al escribir superblock: %v", err)
+	if err := sb.Serialize(dev, partStart); err != nil {
+		return "", fmt.Errorf("error al escribir superblock: %v", err)
 	}
 
-	// 6) Inicializar Journal si es EXT3
-	if fs == "3fs" {
-		journalStart := partStart + 68
-		// Inicializar 50 journals vacíos
-		emptyJournal := make([]byte, 256)
-		for i := 0; i < 50; i++ {
-			if _, err := f.Seek(journalStart+int64(i*256), 0); err != nil {
-				return fmt.Errorf("error al posicionar journal: %v", err)
-			}
-			if _, err := f.Write(emptyJournal); err != nil {
-				return fmt.Errorf("error al escribir journal: %v", err)
-			}
+	// 6) Inicializar Journal vacío si es EXT3 (también con fast: no deben quedar
+	// entradas ni bytes del sistema de archivos anterior)
+	if layout.Ext3 {
+		if err := utils.ZeroFill(dev, layout.JournalStart, layout.JournalSize); err != nil {
+			return "", fmt.Errorf("error al escribir journal: %v", err)
 		}
 	}
 
 	// 7) Inicializar Bitmaps
-	// Bitmap de inodos
-	if _, err := f.Seek(int64(sb.S_bm_inode_start), 0); err != nil {
-		return fmt.Errorf("error al posicionar bitmap inodos: %v", err)
-	}
-	bmInodes := make([]byte, n)
-	bmInodes[0] = 1 // inodo root usado
-	bmInodes[1] = 1 // inodo users.txt usado
-	if _, err := f.Write(bmInodes); err != nil {
-		return fmt.Errorf("error al escribir bitmap inodos: %v", err)
+	// Bitmap de inodos: root (0) y users.txt (1) usados
+	if err := sb.InodeBitmap(dev).Reset(0, 1); err != nil {
+		return "", fmt.Errorf("error al escribir bitmap inodos: %v", err)
 	}
 
-	// Bitmap de bloques
-	if _, err := f.Seek(int64(sb.S_bm_block_start), 0); err != nil {
-		return fmt.Errorf("error al posicionar bitmap bloques: %v", err)
-	}
-	bmBlocks := make([]byte, 3*n)
-	bmBlocks[0] = 1 // bloque carpeta root usado
-	bmBlocks[1] = 1 // bloque archivo users.txt usado
-	if _, err := f.Write(bmBlocks); err != nil {
-		return fmt.Errorf("error al escribir bitmap bloques: %v", err)
+	// Bitmap de bloques: carpeta root (0) y archivo users.txt (1) usados
+	if err := sb.BlockBitmap(dev).Reset(0, 1); err != nil {
+		return "", fmt.Errorf("error al escribir bitmap bloques: %v", err)
 	}
 
 	// 8) Crear inodo root (inodo 0)
@@ -231,16 +122,16 @@ func Mkfs(id string, ftype string, fs string) error {
 		I_uid:   1,
 		I_gid:   1,
 		I_size:  0,
-		I_atime: float32(time.Now().Unix()),
-		I_ctime: float32(time.Now().Unix()),
-		I_mtime: float32(time.Now().Unix()),
+		I_atime: time.Now().UnixNano(),
+		I_ctime: time.Now().UnixNano(),
+		I_mtime: time.Now().UnixNano(),
 		I_block: [15]int32{0, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
 		I_type:  [1]byte{'0'}, // carpeta
 		I_perm:  [3]byte{'7', '7', '7'},
 	}
 
-	if err := rootInode.Serialize(diskPath, int64(sb.S_inode_start)); err != nil {
-		return fmt.Errorf("error al escribir inodo root: %v", err)
+	if err := rootInode.Serialize(dev, layout.InodeOffset(0)); err != nil {
+		return "", fmt.Errorf("error al escribir inodo root: %v", err)
 	}
 
 	// 9) Crear inodo users.txt (inodo 1)
@@ -249,16 +140,16 @@ func Mkfs(id string, ftype string, fs string) error {
 		I_uid:   1,
 		I_gid:   1,
 		I_size:  int32(len(usersContent)),
-		I_atime: float32(time.Now().Unix()),
-		I_ctime: float32(time.Now().Unix()),
-		I_mtime: float32(time.Now().Unix()),
+		I_atime: time.Now().UnixNano(),
+		I_ctime: time.Now().UnixNano(),
+		I_mtime: time.Now().UnixNano(),
 		I_block: [15]int32{1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
 		I_type:  [1]byte{'1'}, // archivo
 		I_perm:  [3]byte{'6', '6', '4'},
 	}
 
-	if err := usersInode.Serialize(diskPath, int64(sb.S_inode_start+128)); err != nil {
-		return fmt.Errorf("error al escribir inodo users: %v", err)
+	if err := usersInode.Serialize(dev, layout.InodeOffset(1)); err != nil {
+		return "", fmt.Errorf("error al escribir inodo users: %v", err)
 	}
 
 	// 10) Crear bloque carpeta root (bloque 0)
@@ -271,21 +162,21 @@ func Mkfs(id string, ftype string, fs string) error {
 		},
 	}
 
-	if err := rootBlock.Serialize(diskPath, int64(sb.S_block_start)); err != nil {
-		return fmt.Errorf("error al escribir bloque root: %v", err)
+	if err := rootBlock.Serialize(dev, layout.BlockOffset(0)); err != nil {
+		return "", fmt.Errorf("error al escribir bloque root: %v", err)
 	}
 
 	// 11) Crear bloque archivo users.txt (bloque 1)
 	usersBlock := structures.FileBlock{}
 	copy(usersBlock.B_content[:], usersContent)
 
-	if err := usersBlock.Serialize(diskPath, int64(sb.S_block_start+64)); err != nil {
-		return fmt.Errorf("error al escribir bloque users: %v", err)
+	if err := usersBlock.Serialize(dev, layout.BlockOffset(1)); err != nil {
+		return "", fmt.Errorf("error al escribir bloque users: %v", err)
 	}
 
 	// 12) Actualizar superblock con valores finales
-	if err := sb.Serialize(diskPath, partStart); err != nil {
-		return fmt.Errorf("error al actualizar superblock: %v", err)
+	if err := sb.Serialize(dev, partStart); err != nil {
+		return "", fmt.Errorf("error al actualizar superblock: %v", err)
 	}
 
 	fsType := "EXT2"
@@ -301,5 +192,105 @@ func Mkfs(id string, ftype string, fs string) error {
 	fmt.Printf("  S_inode_start: %d\n", sb.S_inode_start)
 	fmt.Printf("  S_block_start: %d\n", sb.S_block_start)
 
-	return nil
+	return warning, nil
+}
+
+// resolveMkfsTarget obtiene el disco, el inicio y el tamaño de la partición montada
+func resolveMkfsTarget(id string) (string, int64, int64, error) {
+	pathEntry, ok := stores.MountedPartitions[id]
+	if !ok {
+		// Búsqueda case-insensitive
+		for k, v := range stores.MountedPartitions {
+			if strings.EqualFold(k, id) {
+				pathEntry = v
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return "", 0, 0, fmt.Errorf("partición %s no encontrada o no montada", id)
+		}
+	}
+
+	// Parsear pathEntry: formato esperado "path|start|size"
+	var diskPath string
+	var partStart int64
+	var partSize int64
+
+	if strings.Contains(pathEntry, "|") {
+		parts := strings.Split(pathEntry, "|")
+		diskPath = parts[0]
+		if len(parts) > 1 {
+			fmt.Sscan(parts[1], &partStart)
+		}
+		if len(parts) > 2 {
+			fmt.Sscan(parts[2], &partSize)
+		}
+	} else {
+		diskPath = pathEntry
+		// Necesitamos obtener start y size del MBR (o del EBR si es lógica)
+		partition, _, err := stores.GetMountedPartition(id)
+		if err != nil {
+			return "", 0, 0, fmt.Errorf("partición no encontrada en el disco: %v", err)
+		}
+
+		partStart = int64(partition.Part_start)
+		partSize = int64(partition.Part_size)
+	}
+
+	return diskPath, partStart, partSize, nil
+}
+
+// planMkfs informa las estructuras que escribiría mkfs y los inodos que se perderían
+func planMkfs(id string, ftype string, fs string, packed bool) (string, error) {
+	diskPath, partStart, partSize, err := resolveMkfsTarget(id)
+	if err != nil {
+		return "", err
+	}
+
+	plan := newDryRunPlan("MKFS")
+	layout := structures.NewLayout(partStart, partSize, fs == "3fs", packed)
+
+	// Mismas zonas que escribe Mkfs
+	if ftype == "full" {
+		plan.addRange(partStart, partStart+partSize, "toda la partición con ceros (type=full)")
+	}
+	plan.addRange(partStart, layout.JournalStart, "superbloque")
+	if layout.Ext3 {
+		plan.addRange(layout.JournalStart, layout.BmInodeStart, fmt.Sprintf("journal (%d entradas vacías)", structures.JournalEntries))
+	}
+	plan.addRange(layout.BmInodeStart, layout.BmBlockStart, fmt.Sprintf("bitmap de inodos (%d)", layout.Inodes))
+	plan.addRange(layout.BmBlockStart, layout.InodeStart, fmt.Sprintf("bitmap de bloques (%d)", layout.Blocks()))
+	plan.addRange(layout.InodeOffset(0), layout.InodeOffset(2), "inodos 0 (raíz) y 1 (users.txt)")
+	plan.addRange(layout.BlockOffset(0), layout.BlockOffset(2), "bloques 0 (carpeta raíz) y 1 (users.txt)")
+
+	if !layout.Fits() {
+		plan.addNote("las estructuras terminan en el byte %d, después del final de la partición (%d)", layout.End, partStart+partSize)
+	}
+
+	dev, err := stores.OpenDevice(diskPath)
+	if err != nil {
+		return "", fmt.Errorf("no se pudo abrir disco %s: %v", diskPath, err)
+	}
+	if warning := existingFilesystemWarning(dev, id, partStart); warning != "" {
+		plan.addNote("%s", warning)
+	}
+	plan.addFreedInodes(dev, id, partStart, -1)
+	return plan.String(), nil
+}
+
+// existingFilesystemWarning describe el sistema de archivos que ya tiene la partición,
+// o devuelve "" si no tiene un S_magic válido
+func existingFilesystemWarning(dev structures.Device, id string, partStart int64) string {
+	sb := structures.SuperBlock{}
+	if err := sb.Deserialize(dev, partStart); err != nil || sb.S_magic != 0xEF53 {
+		return ""
+	}
+
+	fsName := "EXT2"
+	if sb.S_filesystem_type == 3 {
+		fsName = "EXT3"
+	}
+	return fmt.Sprintf("la partición %s ya tenía un sistema de archivos %s (%d inodos usados); se reemplaza",
+		id, fsName, sb.S_inodes_count-sb.S_free_inodes_count)
 }
@@ -3,9 +3,9 @@ package commands
 import (
 	"backend/stores"
 	"backend/structures"
+	"backend/utils"
 	"errors"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 )
@@ -27,51 +27,13 @@ type MKUSR struct {
 	El usuario no debe existir previamente
 */
 
-// ParseMkusr analiza los tokens del comando mkusr
-func ParseMkusr(tokens []string) (string, error) {
+// ParseMkusr analiza los parámetros del comando mkusr
+func ParseMkusr(params utils.Params) (string, error) {
 	cmd := &MKUSR{}
 
-	// Procesar cada token
-	for _, token := range tokens {
-		token = strings.TrimSpace(token)
-		lowerToken := strings.ToLower(token)
-
-		if strings.HasPrefix(lowerToken, "-user=") {
-			value := token[len("-user="):]
-			// Quitar comillas si existen
-			if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-				value = strings.Trim(value, "\"")
-			}
-			cmd.user = value
-		} else if strings.HasPrefix(lowerToken, "-pass=") {
-			value := token[len("-pass="):]
-			// Quitar comillas si existen
-			if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-				value = strings.Trim(value, "\"")
-			}
-			cmd.pass = value
-		} else if strings.HasPrefix(lowerToken, "-grp=") {
-			value := token[len("-grp="):]
-			// Quitar comillas si existen
-			if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-				value = strings.Trim(value, "\"")
-			}
-			cmd.grp = value
-		} else if token != "" && token != "mkusr" {
-			return "", fmt.Errorf("MKUSR ERROR: parámetro no reconocido '%s'", token)
-		}
-	}
-
-	// Validar parámetros obligatorios
-	if cmd.user == "" {
-		return "", errors.New("MKUSR ERROR: el parámetro -user es obligatorio")
-	}
-	if cmd.pass == "" {
-		return "", errors.New("MKUSR ERROR: el parámetro -pass es obligatorio")
-	}
-	if cmd.grp == "" {
-		return "", errors.New("MKUSR ERROR: el parámetro -grp es obligatorio")
-	}
+	cmd.user = params.Value("user")
+	cmd.pass = params.Value("pass")
+	cmd.grp = params.Value("grp")
 
 	// Validar longitud máxima (10 caracteres según el enunciado)
 	if len(cmd.user) > 10 {
@@ -101,7 +63,7 @@ func commandMkusr(cmd *MKUSR) error {
 	}
 
 	// 2. Verificar que el usuario actual es root
-	currentUser, _, _ := stores.Auth.GetCurrentUser()
+	currentUser, _ := stores.Auth.GetCurrentUser()
 	if currentUser != "root" {
 		return errors.New("MKUSR ERROR: Solo el usuario root puede crear usuarios")
 	}
@@ -112,9 +74,13 @@ func commandMkusr(cmd *MKUSR) error {
 	if err != nil {
 		return fmt.Errorf("MKUSR ERROR: error al obtener la partición montada: %w", err)
 	}
+	dev, err := stores.OpenDevice(diskPath)
+	if err != nil {
+		return fmt.Errorf("MKUSR ERROR: error al abrir el disco: %w", err)
+	}
 
 	// 4. Leer el contenido actual de users.txt
-	usersContent, err := readUsersFileMkusr(sb, diskPath)
+	usersContent, err := readUsersFileMkusr(sb, dev)
 	if err != nil {
 		return fmt.Errorf("MKUSR ERROR: error al leer users.txt: %w", err)
 	}
@@ -174,17 +140,19 @@ func commandMkusr(cmd *MKUSR) error {
 	}
 
 	// 8. Crear la nueva línea de usuario
-	// Formato: UID, Tipo, Grupo, Usuario, Contraseña
-	newUserLine := fmt.Sprintf("%d,U,%s,%s,%s", nextUID, cmd.grp, cmd.user, cmd.pass)
+	// Formato: UID, Tipo, Grupo, Usuario, Contraseña (hash con sal, ver utils.HashPassword)
+	hashedPass, err := utils.HashPassword(cmd.pass)
+	if err != nil {
+		return fmt.Errorf("MKUSR ERROR: %w", err)
+	}
+	newUserLine := fmt.Sprintf("%d,U,%s,%s,%s", nextUID, cmd.grp, cmd.user, hashedPass)
 	validLines = append(validLines, newUserLine)
 
 	// 9. Reconstruir el contenido completo
 	newContent := strings.Join(validLines, "\n") + "\n"
 
-	fmt.Printf("DEBUG MKUSR -> Nuevo contenido de users.txt:\n%s\n", newContent)
-
 	// 10. Escribir el nuevo contenido en users.txt
-	err = writeUsersFileMkusr(sb, partition, diskPath, newContent)
+	err = writeUsersFileMkusr(sb, partition, dev, newContent)
 	if err != nil {
 		return fmt.Errorf("MKUSR ERROR: error al escribir users.txt: %w", err)
 	}
@@ -193,11 +161,11 @@ func commandMkusr(cmd *MKUSR) error {
 }
 
 // readUsersFileMkusr lee el contenido completo del archivo users.txt (inodo 1)
-func readUsersFileMkusr(sb *structures.SuperBlock, path string) (string, error) {
+func readUsersFileMkusr(sb *structures.SuperBlock, dev structures.Device) (string, error) {
 	// Deserializar el inodo 1 (users.txt)
 	inode := &structures.Inode{}
-	inodeOffset := int64(sb.S_inode_start + (1 * sb.S_inode_size))
-	err := inode.Deserialize(path, inodeOffset)
+	inodeOffset := sb.InodeOffset(1)
+	err := inode.Deserialize(dev, inodeOffset)
 	if err != nil {
 		return "", fmt.Errorf("error al deserializar inodo users.txt: %w", err)
 	}
@@ -218,8 +186,8 @@ func readUsersFileMkusr(sb *structures.SuperBlock, path string) (string, error)
 		}
 
 		block := &structures.FileBlock{}
-		blockOffset := int64(sb.S_block_start + (blockIndex * sb.S_block_size))
-		err := block.Deserialize(path, blockOffset)
+		blockOffset := sb.BlockOffset(int64(blockIndex))
+		err := block.Deserialize(dev, blockOffset)
 		if err != nil {
 			return "", fmt.Errorf("error al deserializar bloque %d: %w", blockIndex, err)
 		}
@@ -233,11 +201,11 @@ func readUsersFileMkusr(sb *structures.SuperBlock, path string) (string, error)
 }
 
 // writeUsersFileMkusr escribe el contenido actualizado en users.txt
-func writeUsersFileMkusr(sb *structures.SuperBlock, partition *structures.Partition, path string, content string) error {
+func writeUsersFileMkusr(sb *structures.SuperBlock, partition *structures.Partition, dev structures.Device, content string) error {
 	// Deserializar el inodo 1 (users.txt)
 	inode := &structures.Inode{}
-	inodeOffset := int64(sb.S_inode_start + (1 * sb.S_inode_size))
-	err := inode.Deserialize(path, inodeOffset)
+	inodeOffset := sb.InodeOffset(1)
+	err := inode.Deserialize(dev, inodeOffset)
 	if err != nil {
 		return fmt.Errorf("error al deserializar inodo users.txt: %w", err)
 	}
@@ -251,27 +219,17 @@ func writeUsersFileMkusr(sb *structures.SuperBlock, partition *structures.Partit
 	inode.I_size = int32(len(content))
 
 	// Actualizar timestamps
-	inode.I_mtime = float32(time.Now().Unix()) // Última modificación
-	inode.I_atime = float32(time.Now().Unix()) // Último acceso
+	inode.I_mtime = time.Now().UnixNano() // Última modificación
+	inode.I_atime = time.Now().UnixNano() // Último acceso
 
-	// Calcular cuántos bloques necesitamos (cada bloque tiene 64 bytes)
+	// Calcular cuántos bloques necesitamos (FileBlockContentSize bytes por bloque)
 	contentBytes := []byte(content)
-	blocksNeeded := (len(contentBytes) + 63) / 64 // Redondear hacia arriba
+	blocksNeeded := (len(contentBytes) + structures.FileBlockContentSize - 1) / structures.FileBlockContentSize // Redondear hacia arriba
 
 	if blocksNeeded > 12 {
 		return errors.New("el contenido de users.txt excede la capacidad de 12 bloques directos")
 	}
 
-	fmt.Printf("DEBUG writeUsersFileMkusr -> Bloques necesarios: %d\n", blocksNeeded)
-	fmt.Printf("DEBUG writeUsersFileMkusr -> Contenido length: %d bytes\n", len(contentBytes))
-
-	// Abrir el archivo para escritura
-	file, err := os.OpenFile(path, os.O_RDWR, 0644)
-	if err != nil {
-		return fmt.Errorf("error al abrir archivo: %w", err)
-	}
-	defer file.Close()
-
 	// Escribir el contenido en los bloques
 	for i := 0; i < blocksNeeded; i++ {
 		blockIndex := inode.I_block[i]
@@ -279,7 +237,7 @@ func writeUsersFileMkusr(sb *structures.SuperBlock, partition *structures.Partit
 		// Si el bloque no existe, necesitamos asignar uno nuevo
 		if blockIndex == -1 {
 			// Buscar el siguiente bloque libre en el bitmap
-			blockIndex, err = findFreeBlock(sb, file)
+			blockIndex, err = findFreeBlock(sb, dev)
 			if err != nil {
 				return fmt.Errorf("error al buscar bloque libre: %w", err)
 			}
@@ -288,7 +246,7 @@ func writeUsersFileMkusr(sb *structures.SuperBlock, partition *structures.Partit
 			inode.I_block[i] = blockIndex
 
 			// Marcar el bloque como usado en el bitmap
-			err = updateBitmapBlockMkusr(sb, file, blockIndex, true)
+			err = updateBitmapBlockMkusr(sb, dev, blockIndex, true)
 			if err != nil {
 				return fmt.Errorf("error al actualizar bitmap: %w", err)
 			}
@@ -301,14 +259,14 @@ func writeUsersFileMkusr(sb *structures.SuperBlock, partition *structures.Partit
 		block := &structures.FileBlock{}
 
 		// Calcular el rango de bytes para este bloque
-		startByte := i * 64
-		endByte := startByte + 64
+		startByte := i * structures.FileBlockContentSize
+		endByte := startByte + structures.FileBlockContentSize
 		if endByte > len(contentBytes) {
 			endByte = len(contentBytes)
 		}
 
 		// Copiar el contenido al bloque (rellenar con zeros el resto)
-		for j := 0; j < 64; j++ {
+		for j := 0; j < structures.FileBlockContentSize; j++ {
 			if startByte+j < len(contentBytes) {
 				block.B_content[j] = contentBytes[startByte+j]
 			} else {
@@ -317,10 +275,9 @@ func writeUsersFileMkusr(sb *structures.SuperBlock, partition *structures.Partit
 		}
 
 		// Escribir el bloque en el disco
-		blockOffset := int64(sb.S_block_start + (blockIndex * sb.S_block_size))
-		fmt.Printf("DEBUG writeUsersFileMkusr -> Escribiendo bloque %d en offset %d\n", blockIndex, blockOffset)
+		blockOffset := sb.BlockOffset(int64(blockIndex))
 
-		err := block.Serialize(path, blockOffset)
+		err := block.Serialize(dev, blockOffset)
 		if err != nil {
 			return fmt.Errorf("error al escribir bloque %d: %w", blockIndex, err)
 		}
@@ -330,15 +287,15 @@ func writeUsersFileMkusr(sb *structures.SuperBlock, partition *structures.Partit
 	for i := blocksNeeded; i < 12; i++ {
 		if inode.I_block[i] != -1 {
 			// Marcar el bloque como libre
-			err = updateBitmapBlockMkusr(sb, file, inode.I_block[i], false)
+			err = updateBitmapBlockMkusr(sb, dev, inode.I_block[i], false)
 			if err != nil {
 				return fmt.Errorf("error al liberar bloque: %w", err)
 			}
 
 			// Limpiar el bloque
 			block := &structures.FileBlock{}
-			blockOffset := int64(sb.S_block_start + (inode.I_block[i] * sb.S_block_size))
-			err := block.Serialize(path, blockOffset)
+			blockOffset := sb.BlockOffset(int64(inode.I_block[i]))
+			err := block.Serialize(dev, blockOffset)
 			if err != nil {
 				return fmt.Errorf("error al limpiar bloque %d: %w", i, err)
 			}
@@ -350,69 +307,28 @@ func writeUsersFileMkusr(sb *structures.SuperBlock, partition *structures.Partit
 	}
 
 	// Actualizar el inodo en el disco
-	err = inode.Serialize(path, inodeOffset)
+	err = inode.Serialize(dev, inodeOffset)
 	if err != nil {
 		return fmt.Errorf("error al escribir inodo users.txt: %w", err)
 	}
 
 	// Actualizar el superbloque en el disco
 	sbOffset := int64(partition.Part_start)
-	err = sb.Serialize(path, sbOffset)
+	err = sb.Serialize(dev, sbOffset)
 	if err != nil {
 		return fmt.Errorf("error al actualizar superbloque: %w", err)
 	}
 
-	fmt.Println("DEBUG writeUsersFileMkusr -> Escritura completada exitosamente")
 	return nil
 }
 
 // findFreeBlock busca el primer bloque libre en el bitmap
-func findFreeBlock(sb *structures.SuperBlock, file *os.File) (int32, error) {
-	// Leer el bitmap de bloques
-	bitmapSize := sb.S_blocks_count
-	bitmap := make([]byte, bitmapSize)
-
-	_, err := file.Seek(int64(sb.S_bm_block_start), 0)
-	if err != nil {
-		return -1, err
-	}
-
-	_, err = file.Read(bitmap)
-	if err != nil {
-		return -1, err
-	}
-
-	// Buscar el primer bit libre (0)
-	for i := int32(0); i < bitmapSize; i++ {
-		if bitmap[i] == 0 {
-			return i, nil
-		}
-	}
-
-	return -1, errors.New("no hay bloques libres disponibles")
+func findFreeBlock(sb *structures.SuperBlock, dev structures.Device) (int32, error) {
+	index, err := sb.BlockBitmap(dev).FirstFree()
+	return int32(index), err
 }
 
 // updateBitmapBlockMkusr actualiza el bitmap de bloques
-func updateBitmapBlockMkusr(sb *structures.SuperBlock, file *os.File, blockIndex int32, used bool) error {
-	// Posicionarse en el bitmap de bloques
-	bitmapOffset := int64(sb.S_bm_block_start + blockIndex)
-	_, err := file.Seek(bitmapOffset, 0)
-	if err != nil {
-		return err
-	}
-
-	// Escribir el bit
-	var bit byte
-	if used {
-		bit = 1
-	} else {
-		bit = 0
-	}
-
-	_, err = file.Write([]byte{bit})
-	if err != nil {
-		return err
-	}
-
-	return nil
+func updateBitmapBlockMkusr(sb *structures.SuperBlock, dev structures.Device, blockIndex int32, used bool) error {
+	return sb.BlockBitmap(dev).Set(int64(blockIndex), used)
 }
@@ -6,8 +6,8 @@ import (
 	utils "backend/utils"
 	"errors"
 	"fmt"
-	"regexp"
 	"strings"
+	"time"
 )
 
 // MOUNT estructura que representa el comando mount con sus parámetros
@@ -17,46 +17,11 @@ type MOUNT struct {
 }
 
 // ParseMount parsea el comando mount y devuelve una instancia de MOUNT
-func ParseMount(tokens []string) (string, error) {
+func ParseMount(params utils.Params) (string, error) {
 	cmd := &MOUNT{}
 
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+|-name="[^"]+"|-name=[^\s]+`)
-	matches := re.FindAllString(args, -1)
-
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parámetro inválido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
-
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
-
-		switch key {
-		case "-path":
-			if value == "" {
-				return "", errors.New("el path no puede estar vacío")
-			}
-			cmd.path = value
-		case "-name":
-			if value == "" {
-				return "", errors.New("el nombre no puede estar vacío")
-			}
-			cmd.name = value
-		default:
-			return "", fmt.Errorf("parámetro desconocido: %s", key)
-		}
-	}
-
-	if cmd.path == "" {
-		return "", errors.New("faltan parámetros requeridos: -path")
-	}
-	if cmd.name == "" {
-		return "", errors.New("faltan parámetros requeridos: -name")
-	}
+	cmd.path = params.Value("path")
+	cmd.name = params.Value("name")
 
 	// Montamos la partición
 	idPartition, err := commandMount(cmd)
@@ -72,10 +37,15 @@ func ParseMount(tokens []string) (string, error) {
 }
 
 func commandMount(mount *MOUNT) (string, error) {
+	dev, err := stores.OpenDevice(mount.path)
+	if err != nil {
+		return "", fmt.Errorf("error abriendo el disco: %v", err)
+	}
+
 	var mbr structures.MBR
 
 	// Deserializar MBR
-	err := mbr.Deserialize(mount.path)
+	err = mbr.Deserialize(dev)
 	if err != nil {
 		return "", fmt.Errorf("error leyendo MBR: %v", err)
 	}
@@ -83,12 +53,13 @@ func commandMount(mount *MOUNT) (string, error) {
 	// Buscar la partición con el nombre especificado
 	partition, indexPartition := mbr.GetPartitionByName(mount.name)
 	if partition == nil {
-		return "", errors.New("ERROR: la partición no existe")
+		// Si no está en el MBR puede ser una lógica dentro de la extendida
+		return mountLogicalPartition(mount, dev, &mbr)
 	}
 
-	// VALIDACIÓN: Solo se pueden montar particiones PRIMARIAS
-	if partition.Part_type[0] != 'P' {
-		return "", errors.New("ERROR: solo se pueden montar particiones primarias")
+	// VALIDACIÓN: La extendida no tiene sistema de archivos propio
+	if partition.Part_type[0] == 'E' {
+		return "", errors.New("ERROR: no se puede montar una partición extendida, monte sus particiones lógicas")
 	}
 
 	// VALIDACIÓN: Verificar que la partición no esté ya montada
@@ -106,7 +77,7 @@ func commandMount(mount *MOUNT) (string, error) {
 	}
 
 	// Generar un ID único para la partición
-	idPartition, partitionCorrelative, err := generatePartitionID(mount)
+	idPartition, partitionCorrelative, err := generatePartitionID(mount, &mbr)
 	if err != nil {
 		return "", fmt.Errorf("error generando ID de partición: %v", err)
 	}
@@ -124,24 +95,96 @@ func commandMount(mount *MOUNT) (string, error) {
 	mbr.Mbr_partitions[indexPartition] = *partition
 
 	// Serializar MBR
-	err = mbr.Serialize(mount.path)
+	err = mbr.Serialize(dev)
 	if err != nil {
 		return "", fmt.Errorf("error guardando MBR: %v", err)
 	}
 
+	if err := updateMountTimes(dev, int64(partition.Part_start), true); err != nil {
+		return "", fmt.Errorf("error actualizando el superbloque: %v", err)
+	}
+
 	return idPartition, nil
 }
 
-func generatePartitionID(mount *MOUNT) (string, int, error) {
-	// Asignar letra y obtener correlativo de partición
-	letter, partitionCorrelative, err := utils.GetLetterAndPartitionCorrelative(mount.path)
+// mountLogicalPartition monta una lógica buscándola en la cadena de EBR. El estado se
+// guarda en Part_mount del EBR y la posición del EBR en stores.MountedLogicals.
+func mountLogicalPartition(mount *MOUNT, dev structures.Device, mbr *structures.MBR) (string, error) {
+	ebr, position, err := findLogicalPartition(dev, mbr, mount.name)
 	if err != nil {
-		return "", 0, err
+		return "", err
 	}
 
-	// Crear ID de partición: últimos 2 dígitos del carnet + número partición + letra
-	// Ejemplo: carnet = 202401234 -> 34 + 1 + A = 341A
-	idPartition := fmt.Sprintf("%s%d%s", stores.Carnet, partitionCorrelative, strings.ToUpper(letter))
+	// Part_mount puede quedar en '1' de una ejecución anterior; lo que cuenta es el montaje en memoria
+	for id, mounted := range stores.MountedLogicals {
+		if mounted == position && stores.MountedPartitions[id] == mount.path {
+			return "", fmt.Errorf("ERROR: la partición '%s' ya está montada con ID: %s", mount.name, id)
+		}
+	}
+
+	idPartition, _, err := generatePartitionID(mount, mbr)
+	if err != nil {
+		return "", fmt.Errorf("error generando ID de partición: %v", err)
+	}
+	idPartition = strings.ToUpper(strings.TrimSpace(idPartition))
+
+	ebr.Part_mount = [1]byte{'1'}
+	if err := ebr.Serialize(dev, int(position)); err != nil {
+		return "", fmt.Errorf("error guardando EBR: %v", err)
+	}
+
+	stores.MountedPartitions[idPartition] = mount.path
+	stores.MountedLogicals[idPartition] = position
+
+	if err := updateMountTimes(dev, int64(ebr.Part_start), true); err != nil {
+		return "", fmt.Errorf("error actualizando el superbloque: %v", err)
+	}
+
+	return idPartition, nil
+}
+
+// updateMountTimes registra el montaje (S_mtime, S_mnt_count) o el desmontaje (S_umtime)
+// en el superbloque. Si la partición aún no está formateada no hay nada que actualizar.
+func updateMountTimes(dev structures.Device, partStart int64, mounting bool) error {
+	sb := structures.SuperBlock{}
+	if err := sb.Deserialize(dev, partStart); err != nil || sb.S_magic != 0xEF53 {
+		return nil
+	}
+
+	now := time.Now().UnixNano()
+	if mounting {
+		sb.S_mtime = now
+		sb.S_mnt_count++
+	} else {
+		sb.S_umtime = now
+	}
+	return sb.Serialize(dev, partStart)
+}
+
+// findLogicalPartition busca una lógica por nombre y devuelve su EBR y la posición del EBR
+func findLogicalPartition(dev structures.Device, mbr *structures.MBR, name string) (*structures.EBR, int64, error) {
+	for i := range mbr.Mbr_partitions {
+		extended := &mbr.Mbr_partitions[i]
+		if extended.Part_start == -1 || extended.Part_type[0] != 'E' {
+			continue
+		}
+
+		for _, position := range ebrChainPositions(dev, int64(extended.Part_start)) {
+			ebr := &structures.EBR{}
+			if err := ebr.Deserialize(dev, int(position)); err != nil {
+				return nil, 0, fmt.Errorf("error leyendo EBR: %v", err)
+			}
+			ebrName := strings.Trim(string(ebr.Part_name[:]), "\x00 ")
+			if !ebr.IsEmpty() && strings.EqualFold(ebrName, strings.TrimSpace(name)) {
+				return ebr, position, nil
+			}
+		}
+	}
+	return nil, 0, errors.New("ERROR: la partición no existe")
+}
 
-	return idPartition, partitionCorrelative, nil
+// generatePartitionID prefijo configurado + correlativo + letra del disco, p. ej. 391A.
+// La letra se asigna por la firma del MBR y el correlativo libre más bajo se reutiliza.
+func generatePartitionID(mount *MOUNT, mbr *structures.MBR) (string, int, error) {
+	return stores.AllocatePartitionID(mount.path, mbr.Mbr_disk_signature)
 }
@@ -1,20 +1,16 @@
 package commands
 
 import (
-	"errors"
 	"fmt"
 	"sort"
 	"strings"
 
 	stores "backend/stores"
+	utils "backend/utils"
 )
 
 // ParseMounted procesa el comando 'mounted'
-func ParseMounted(tokens []string) (string, error) {
-	joined := strings.TrimSpace(strings.Join(tokens, " "))
-	if joined != "" {
-		return "", errors.New("parámetro desconocido para 'mounted'")
-	}
+func ParseMounted(params utils.Params) (string, error) {
 	return commandMounted()
 }
 
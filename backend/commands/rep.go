@@ -3,10 +3,8 @@ package commands
 import (
 	reports "backend/reports"
 	stores "backend/stores"
-	"errors"
+	utils "backend/utils"
 	"fmt"
-	"regexp"
-	"strings"
 )
 
 // REP estructura que representa el comando rep con sus parámetros
@@ -18,63 +16,13 @@ type REP struct {
 }
 
 // ParserRep parsea el comando rep y devuelve una instancia de REP
-func ParseRep(tokens []string) (string, error) {
+func ParseRep(params utils.Params) (string, error) {
 	cmd := &REP{} // Crea una nueva instancia de REP
 
-	// Unir tokens en una sola cadena y luego dividir por espacios, respetando las comillas
-	args := strings.Join(tokens, " ")
-	// Expresión regular para encontrar los parámetros del comando rep
-	re := regexp.MustCompile(`-(?i:id|path|name|path_file_ls)(="[^"]+"|=[^\s]+)`)
-	// Encuentra todas las coincidencias de la expresión regular en la cadena de argumentos
-	matches := re.FindAllString(args, -1)
-
-	// Itera sobre cada coincidencia encontrada
-	for _, match := range matches {
-		// Divide cada parte en clave y valor usando "=" como delimitador
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parámetro inválido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
-
-		// Remove quotes from value if present
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
-
-		// Switch para manejar diferentes parámetros
-		switch key {
-		case "-id":
-			// Verifica que el id no esté vacío
-			if value == "" {
-				return "", errors.New("el id no puede estar vacío")
-			}
-			cmd.id = value
-		case "-path":
-			// Verifica que el path no esté vacío
-			if value == "" {
-				return "", errors.New("el path no puede estar vacío")
-			}
-			cmd.path = value
-		case "-name":
-			// Verifica que el nombre sea uno de los valores permitidos
-			validNames := []string{"mbr", "disk", "inode", "block", "bm_inode", "bm_block", "sb", "file", "ls"}
-			if !contains(validNames, value) {
-				return "", errors.New("nombre inválido, debe ser uno de los siguientes: mbr, disk, inode, block, bm_inode, bm_block, sb, file, ls")
-			}
-			cmd.name = value
-		case "-path_file_ls":
-			cmd.path_file_ls = value
-		default:
-			// Si el parámetro no es reconocido, devuelve un error
-			return "", fmt.Errorf("parámetro desconocido: %s", key)
-		}
-	}
-
-	// Verifica que los parámetros obligatorios hayan sido proporcionados
-	if cmd.id == "" || cmd.path == "" || cmd.name == "" {
-		return "", errors.New("faltan parámetros requeridos: -id, -path, -name")
-	}
+	cmd.id = params.Value("id")
+	cmd.path = params.Value("path")
+	cmd.name = params.Value("name")
+	cmd.path_file_ls = params.Value("path_file_ls")
 
 	// Aquí se puede agregar la lógica para ejecutar el comando rep con los parámetros proporcionados
 	err := commandRep(cmd)
@@ -97,16 +45,6 @@ func ParseRep(tokens []string) (string, error) {
 		}()), nil
 }
 
-// Función auxiliar para verificar si un valor está en una lista
-func contains(list []string, value string) bool {
-	for _, v := range list {
-		if v == value {
-			return true
-		}
-	}
-	return false
-}
-
 // Ejemplo de función commandRep (debe ser implementada)
 func commandRep(rep *REP) error {
 	// Obtener la partición montada
@@ -114,6 +52,10 @@ func commandRep(rep *REP) error {
 	if err != nil {
 		return err
 	}
+	dev, err := stores.OpenDevice(mountedDiskPath)
+	if err != nil {
+		return err
+	}
 
 	// Switch para manejar diferentes tipos de reportes
 	switch rep.name {
@@ -123,12 +65,17 @@ func commandRep(rep *REP) error {
 			fmt.Printf("Error: %v\n", err)
 		}
 	case "inode":
-		err = reports.ReportInode(mountedSb, mountedDiskPath, rep.path)
+		err = reports.ReportInode(mountedSb, dev, rep.path)
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 		}
 	case "bm_inode":
-		err = reports.ReportBMInode(mountedSb, mountedDiskPath, rep.path)
+		err = reports.ReportBMInode(mountedSb, dev, rep.path)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	case "bm_block":
+		err = reports.ReportBMBlock(mountedSb, dev, rep.path)
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 		}
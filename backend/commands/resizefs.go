@@ -0,0 +1,59 @@
+package commands
+
+import (
+	stores "backend/stores"
+	structures "backend/structures"
+	utils "backend/utils"
+	"fmt"
+)
+
+/*
+	resizefs -id=391A
+	resizefs -id=391A -dryrun
+*/
+
+// ParseResizefs ajusta el sistema de archivos de una partición montada a su tamaño actual
+func ParseResizefs(params utils.Params) (string, error) {
+	id := params.Value("id")
+
+	diskPath, partStart, partSize, err := resolveMkfsTarget(id)
+	if err != nil {
+		return "", fmt.Errorf("RESIZEFS ERROR: %v", err)
+	}
+
+	dev, err := stores.OpenDevice(diskPath)
+	if err != nil {
+		return "", fmt.Errorf("RESIZEFS ERROR: error abriendo el disco: %v", err)
+	}
+
+	sb := structures.SuperBlock{}
+	if err := sb.Deserialize(dev, partStart); err != nil || sb.S_magic != 0xEF53 {
+		return "", fmt.Errorf("RESIZEFS ERROR: la partición %s no tiene un sistema de archivos EXT2/EXT3", id)
+	}
+
+	// Las tablas se mueven conservando los índices, así que I_block y B_inodo no cambian
+	resize, err := planFilesystemResize(dev, partStart, partSize)
+	if err != nil {
+		return "", err
+	}
+	if resize == nil {
+		return fmt.Sprintf("RESIZEFS: el sistema de archivos de %s ya ocupa toda la partición (%d bytes)", id, partSize), nil
+	}
+
+	if params.Has("dryrun") {
+		plan := newDryRunPlan("RESIZEFS")
+		resize.addToPlan(plan, id)
+		return plan.String(), nil
+	}
+
+	if err := resize.apply(dev); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("RESIZEFS: %s de %s ajustado a %d bytes\n"+
+		"-> Inodos: %d -> %d\n"+
+		"-> Bloques: %d -> %d",
+		resize.fsName, id, partSize,
+		resize.inodes, resize.newInodes,
+		3*resize.inodes, 3*resize.newInodes), nil
+}
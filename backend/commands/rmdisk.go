@@ -1,69 +1,134 @@
 package commands
 
 import (
-	"errors"
+	stores "backend/stores"
+	structures "backend/structures"
+	utils "backend/utils"
 	"fmt"
 	"os"
-	"regexp"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 )
 
 type RMDISK struct {
-	path string
+	path   string
+	dryRun bool // Solo informa lo que se eliminaría
+	force  bool // Desmonta las particiones del disco y cierra su sesión en lugar de rechazar
+
+	dev structures.Device // Disco abierto
 }
 
 // ParserRmdisk ejecuta el comando RMDISK conforme al Proyecto 2 (sin confirmación interactiva).
-func ParserRmdisk(tokens []string) (string, error) {
+func ParserRmdisk(params utils.Params) (string, error) {
 	cmd := &RMDISK{}
 
-	args := strings.Join(tokens, " ")
+	cmd.path = params.Value("path")
+	cmd.dryRun = params.Has("dryrun")
+	cmd.force = params.Has("force")
 
-	// Regex que soporta -path="..." o -path=/ruta
-	re := regexp.MustCompile(`-(?i)path=(?:"[^"]+"|\S+)`)
-	matches := re.FindAllString(args, -1)
+	// Verificar existencia del disco (archivo o disco en memoria)
+	dev, err := stores.OpenDevice(cmd.path)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("ERROR: el disco no existe en la ruta indicada -> %s", cmd.path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("ERROR: no se pudo abrir el disco: %v", err)
+	}
+	cmd.dev = dev
 
-	if len(matches) == 0 {
-		return "", errors.New("ERROR: faltan parámetros requeridos: -path")
+	// Solo se eliminan archivos que tienen un MBR válido, para no borrar otro archivo por error
+	var mbr structures.MBR
+	if err := mbr.Deserialize(cmd.dev); err != nil {
+		return "", fmt.Errorf("ERROR: %s no es un disco: no se pudo leer el MBR: %v", cmd.path, err)
+	}
+	if err := mbr.Validate(cmd.dev.Size()); err != nil {
+		return "", fmt.Errorf("ERROR: %s no es un disco válido: %v", cmd.path, err)
 	}
 
-	// Validar parámetros desconocidos
-	for _, token := range tokens {
-		if !strings.HasPrefix(strings.ToLower(token), "-path=") {
-			if strings.HasPrefix(token, "-") {
-				return "", fmt.Errorf("ERROR: parámetro desconocido: %s", token)
-			}
-		}
+	// Los montajes y la sesión quedarían apuntando a un archivo que ya no existe
+	mounted := mountedIDsOnDisk(cmd.path)
+
+	if cmd.dryRun {
+		return planRmdisk(cmd, &mbr, cmd.dev.Size(), mounted), nil
 	}
 
-	// Procesar parámetro -path
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("ERROR: formato de parámetro inválido: %s", match)
-		}
+	if len(mounted) > 0 && !cmd.force {
+		return "", fmt.Errorf("ERROR: el disco tiene particiones montadas (%s); desmóntelas o use -force", strings.Join(mounted, ", "))
+	}
 
-		value := kv[1]
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+	// Intentar eliminar disco
+	if err := stores.RemoveDisk(cmd.path); err != nil {
+		return "", fmt.Errorf("ERROR: no se pudo eliminar el disco: %w", err)
+	}
+	removeUndoSnapshots(cmd.path)
+
+	sessionUser := releaseDisk(cmd.path, mbr.Mbr_disk_signature, mounted)
 
-		if value == "" {
-			return "", errors.New("ERROR: el path no puede estar vacío")
+	// Mensaje limpio y claro para el script
+	msg := fmt.Sprintf("RMDISK: Disco eliminado correctamente -> Path: %s", cmd.path)
+	if len(mounted) > 0 {
+		msg += fmt.Sprintf("\n-> Se desmontaron: %s", strings.Join(mounted, ", "))
+	}
+	if sessionUser != "" {
+		msg += fmt.Sprintf("\n-> Se cerró la sesión de '%s'", sessionUser)
+	}
+	return msg, nil
+}
+
+// mountedIDsOnDisk IDs de las particiones montadas del disco, ordenados
+func mountedIDsOnDisk(diskPath string) []string {
+	var ids []string
+	for id, path := range stores.MountedPartitions {
+		if filepath.Clean(path) == filepath.Clean(diskPath) {
+			ids = append(ids, id)
 		}
+	}
+	sort.Strings(ids)
+	return ids
+}
 
-		cmd.path = value
+// releaseDisk quita los montajes del disco eliminado, cierra la sesión si estaba en uno
+// de ellos y libera la letra del disco. Devuelve el usuario cuya sesión se cerró.
+func releaseDisk(diskPath string, signature int32, mounted []string) string {
+	sessionUser := dropMounts(mounted)
+	if err := stores.ReleaseDiskLetter(signature); err != nil {
+		fmt.Println("ADVERTENCIA:", err)
 	}
+	return sessionUser
+}
 
-	// Verificar existencia del disco
-	if _, err := os.Stat(cmd.path); os.IsNotExist(err) {
-		return "", fmt.Errorf("ERROR: el disco no existe en la ruta indicada -> %s", cmd.path)
+// planRmdisk informa las particiones e inodos que se perderían al eliminar el disco
+func planRmdisk(cmd *RMDISK, mbr *structures.MBR, diskSize int64, mounted []string) string {
+	plan := newDryRunPlan("RMDISK")
+	plan.addRange(0, diskSize, "se eliminaría el archivo completo del disco")
+
+	if len(mounted) > 0 {
+		if cmd.force {
+			plan.addNote("se desmontarían: %s", strings.Join(mounted, ", "))
+			if username, partitionID := stores.Auth.GetCurrentUser(); stores.Auth.IsAuthenticated() && slices.Contains(mounted, strings.ToUpper(partitionID)) {
+				plan.addNote("se cerraría la sesión de '%s'", username)
+			}
+		} else {
+			plan.addNote("se rechazaría: hay particiones montadas (%s); use -force", strings.Join(mounted, ", "))
+		}
 	}
 
-	// Intentar eliminar disco
-	if err := os.Remove(cmd.path); err != nil {
-		return "", fmt.Errorf("ERROR: no se pudo eliminar el disco: %w", err)
+	for i := range mbr.Mbr_partitions {
+		partition := &mbr.Mbr_partitions[i]
+		if partition.Part_start == -1 {
+			continue
+		}
+		plan.addMBRChange("%s se pierde", describePartition(i, partition))
+
+		name := strings.TrimRight(string(partition.Part_name[:]), "\x00")
+		if partition.Part_type[0] == 'E' {
+			plan.addLogicalPartitions(cmd.dev, int64(partition.Part_start), false)
+		} else {
+			plan.addFreedInodes(cmd.dev, name, int64(partition.Part_start), -1)
+		}
 	}
 
-	// Mensaje limpio y claro para el script
-	return fmt.Sprintf("RMDISK: Disco eliminado correctamente -> Path: %s", cmd.path), nil
+	return plan.String()
 }
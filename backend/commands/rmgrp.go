@@ -3,9 +3,9 @@ package commands
 import (
 	"backend/stores"
 	"backend/structures"
+	"backend/utils"
 	"errors"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 )
@@ -24,31 +24,11 @@ type RMGRP struct {
 	Elimina lógicamente (cambia el ID a 0)
 */
 
-// ParseRmgrp analiza los tokens del comando rmgrp
-func ParseRmgrp(tokens []string) (string, error) {
+// ParseRmgrp analiza los parámetros del comando rmgrp
+func ParseRmgrp(params utils.Params) (string, error) {
 	cmd := &RMGRP{}
 
-	// Procesar cada token
-	for _, token := range tokens {
-		token = strings.TrimSpace(token)
-		lowerToken := strings.ToLower(token)
-
-		if strings.HasPrefix(lowerToken, "-name=") {
-			value := token[len("-name="):]
-			// Quitar comillas si existen
-			if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-				value = strings.Trim(value, "\"")
-			}
-			cmd.name = value
-		} else if token != "" && token != "rmgrp" {
-			return "", fmt.Errorf("RMGRP ERROR: parámetro no reconocido '%s'", token)
-		}
-	}
-
-	// Validar parámetro obligatorio
-	if cmd.name == "" {
-		return "", errors.New("RMGRP ERROR: el parámetro -name es obligatorio")
-	}
+	cmd.name = params.Value("name")
 
 	// Ejecutar el comando
 	err := commandRmgrp(cmd)
@@ -67,7 +47,7 @@ func commandRmgrp(cmd *RMGRP) error {
 	}
 
 	// 2. Verificar que el usuario actual es root
-	currentUser, _, _ := stores.Auth.GetCurrentUser()
+	currentUser, _ := stores.Auth.GetCurrentUser()
 	if currentUser != "root" {
 		return errors.New("RMGRP ERROR: Solo el usuario root puede eliminar grupos")
 	}
@@ -78,9 +58,13 @@ func commandRmgrp(cmd *RMGRP) error {
 	if err != nil {
 		return fmt.Errorf("RMGRP ERROR: error al obtener la partición montada: %w", err)
 	}
+	dev, err := stores.OpenDevice(diskPath)
+	if err != nil {
+		return fmt.Errorf("RMGRP ERROR: error al abrir el disco: %w", err)
+	}
 
 	// 4. Leer el contenido actual de users.txt
-	usersContent, err := readUsersFileRmgrp(sb, diskPath)
+	usersContent, err := readUsersFileRmgrp(sb, dev)
 	if err != nil {
 		return fmt.Errorf("RMGRP ERROR: error al leer users.txt: %w", err)
 	}
@@ -128,10 +112,8 @@ func commandRmgrp(cmd *RMGRP) error {
 	// 7. Reconstruir el contenido completo
 	newContent := strings.Join(validLines, "\n") + "\n"
 
-	fmt.Printf("DEBUG RMGRP -> Nuevo contenido de users.txt:\n%s\n", newContent)
-
 	// 8. Escribir el nuevo contenido en users.txt
-	err = writeUsersFileRmgrp(sb, partition, diskPath, newContent)
+	err = writeUsersFileRmgrp(sb, partition, dev, newContent)
 	if err != nil {
 		return fmt.Errorf("RMGRP ERROR: error al escribir users.txt: %w", err)
 	}
@@ -140,11 +122,11 @@ func commandRmgrp(cmd *RMGRP) error {
 }
 
 // readUsersFileRmgrp lee el contenido completo del archivo users.txt (inodo 1)
-func readUsersFileRmgrp(sb *structures.SuperBlock, path string) (string, error) {
+func readUsersFileRmgrp(sb *structures.SuperBlock, dev structures.Device) (string, error) {
 	// Deserializar el inodo 1 (users.txt)
 	inode := &structures.Inode{}
-	inodeOffset := int64(sb.S_inode_start + (1 * sb.S_inode_size))
-	err := inode.Deserialize(path, inodeOffset)
+	inodeOffset := sb.InodeOffset(1)
+	err := inode.Deserialize(dev, inodeOffset)
 	if err != nil {
 		return "", fmt.Errorf("error al deserializar inodo users.txt: %w", err)
 	}
@@ -165,8 +147,8 @@ func readUsersFileRmgrp(sb *structures.SuperBlock, path string) (string, error)
 		}
 
 		block := &structures.FileBlock{}
-		blockOffset := int64(sb.S_block_start + (blockIndex * sb.S_block_size))
-		err := block.Deserialize(path, blockOffset)
+		blockOffset := sb.BlockOffset(int64(blockIndex))
+		err := block.Deserialize(dev, blockOffset)
 		if err != nil {
 			return "", fmt.Errorf("error al deserializar bloque %d: %w", blockIndex, err)
 		}
@@ -180,11 +162,11 @@ func readUsersFileRmgrp(sb *structures.SuperBlock, path string) (string, error)
 }
 
 // writeUsersFileRmgrp escribe el contenido actualizado en users.txt
-func writeUsersFileRmgrp(sb *structures.SuperBlock, partition *structures.Partition, path string, content string) error {
+func writeUsersFileRmgrp(sb *structures.SuperBlock, partition *structures.Partition, dev structures.Device, content string) error {
 	// Deserializar el inodo 1 (users.txt)
 	inode := &structures.Inode{}
-	inodeOffset := int64(sb.S_inode_start + (1 * sb.S_inode_size))
-	err := inode.Deserialize(path, inodeOffset)
+	inodeOffset := sb.InodeOffset(1)
+	err := inode.Deserialize(dev, inodeOffset)
 	if err != nil {
 		return fmt.Errorf("error al deserializar inodo users.txt: %w", err)
 	}
@@ -198,37 +180,30 @@ func writeUsersFileRmgrp(sb *structures.SuperBlock, partition *structures.Partit
 	inode.I_size = int32(len(content))
 
 	// Actualizar timestamps
-	inode.I_mtime = float32(time.Now().Unix())
-	inode.I_atime = float32(time.Now().Unix())
+	inode.I_mtime = time.Now().UnixNano()
+	inode.I_atime = time.Now().UnixNano()
 
 	// Calcular cuántos bloques necesitamos
 	contentBytes := []byte(content)
-	blocksNeeded := (len(contentBytes) + 63) / 64
+	blocksNeeded := (len(contentBytes) + structures.FileBlockContentSize - 1) / structures.FileBlockContentSize
 
 	if blocksNeeded > 12 {
 		return errors.New("el contenido de users.txt excede la capacidad de 12 bloques directos")
 	}
 
-	// Abrir el archivo para escritura
-	file, err := os.OpenFile(path, os.O_RDWR, 0644)
-	if err != nil {
-		return fmt.Errorf("error al abrir archivo: %w", err)
-	}
-	defer file.Close()
-
 	// Escribir el contenido en los bloques
 	for i := 0; i < blocksNeeded; i++ {
 		blockIndex := inode.I_block[i]
 
 		if blockIndex == -1 {
-			blockIndex, err = findFreeBlockRmgrp(sb, file)
+			blockIndex, err = findFreeBlockRmgrp(sb, dev)
 			if err != nil {
 				return fmt.Errorf("error al buscar bloque libre: %w", err)
 			}
 
 			inode.I_block[i] = blockIndex
 
-			err = updateBitmapBlockRmgrp(sb, file, blockIndex, true)
+			err = updateBitmapBlockRmgrp(sb, dev, blockIndex, true)
 			if err != nil {
 				return fmt.Errorf("error al actualizar bitmap: %w", err)
 			}
@@ -238,13 +213,13 @@ func writeUsersFileRmgrp(sb *structures.SuperBlock, partition *structures.Partit
 
 		block := &structures.FileBlock{}
 
-		startByte := i * 64
-		endByte := startByte + 64
+		startByte := i * structures.FileBlockContentSize
+		endByte := startByte + structures.FileBlockContentSize
 		if endByte > len(contentBytes) {
 			endByte = len(contentBytes)
 		}
 
-		for j := 0; j < 64; j++ {
+		for j := 0; j < structures.FileBlockContentSize; j++ {
 			if startByte+j < len(contentBytes) {
 				block.B_content[j] = contentBytes[startByte+j]
 			} else {
@@ -252,22 +227,22 @@ func writeUsersFileRmgrp(sb *structures.SuperBlock, partition *structures.Partit
 			}
 		}
 
-		blockOffset := int64(sb.S_block_start + (blockIndex * sb.S_block_size))
-		err := block.Serialize(path, blockOffset)
+		blockOffset := sb.BlockOffset(int64(blockIndex))
+		err := block.Serialize(dev, blockOffset)
 		if err != nil {
 			return fmt.Errorf("error al escribir bloque %d: %w", blockIndex, err)
 		}
 	}
 
 	// Actualizar el inodo en el disco
-	err = inode.Serialize(path, inodeOffset)
+	err = inode.Serialize(dev, inodeOffset)
 	if err != nil {
 		return fmt.Errorf("error al escribir inodo users.txt: %w", err)
 	}
 
 	// Actualizar el superbloque en el disco
 	sbOffset := int64(partition.Part_start)
-	err = sb.Serialize(path, sbOffset)
+	err = sb.Serialize(dev, sbOffset)
 	if err != nil {
 		return fmt.Errorf("error al actualizar superbloque: %w", err)
 	}
@@ -276,44 +251,12 @@ func writeUsersFileRmgrp(sb *structures.SuperBlock, partition *structures.Partit
 }
 
 // findFreeBlockRmgrp busca el primer bloque libre en el bitmap
-func findFreeBlockRmgrp(sb *structures.SuperBlock, file *os.File) (int32, error) {
-	bitmapSize := sb.S_blocks_count
-	bitmap := make([]byte, bitmapSize)
-
-	_, err := file.Seek(int64(sb.S_bm_block_start), 0)
-	if err != nil {
-		return -1, err
-	}
-
-	_, err = file.Read(bitmap)
-	if err != nil {
-		return -1, err
-	}
-
-	for i := int32(0); i < bitmapSize; i++ {
-		if bitmap[i] == 0 {
-			return i, nil
-		}
-	}
-
-	return -1, errors.New("no hay bloques libres disponibles")
+func findFreeBlockRmgrp(sb *structures.SuperBlock, dev structures.Device) (int32, error) {
+	index, err := sb.BlockBitmap(dev).FirstFree()
+	return int32(index), err
 }
 
 // updateBitmapBlockRmgrp actualiza el bitmap de bloques
-func updateBitmapBlockRmgrp(sb *structures.SuperBlock, file *os.File, blockIndex int32, used bool) error {
-	bitmapOffset := int64(sb.S_bm_block_start + blockIndex)
-	_, err := file.Seek(bitmapOffset, 0)
-	if err != nil {
-		return err
-	}
-
-	var bit byte
-	if used {
-		bit = 1
-	} else {
-		bit = 0
-	}
-
-	_, err = file.Write([]byte{bit})
-	return err
+func updateBitmapBlockRmgrp(sb *structures.SuperBlock, dev structures.Device, blockIndex int32, used bool) error {
+	return sb.BlockBitmap(dev).Set(int64(blockIndex), used)
 }
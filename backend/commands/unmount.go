@@ -3,53 +3,44 @@ package commands
 import (
 	stores "backend/stores"
 	structures "backend/structures"
-	"errors"
+	utils "backend/utils"
 	"fmt"
-	"regexp"
 	"strings"
 )
 
 // UNMOUNT estructura que representa el comando unmount
 type UNMOUNT struct {
-	id string // ID de la partición a desmontar
+	id    string // ID de la partición a desmontar
+	force bool   // Cierra la sesión activa en la partición en lugar de rechazar el desmontaje
 }
 
 // ParseUnmount parsea el comando unmount y desmonta la partición
-func ParseUnmount(tokens []string) (string, error) {
+func ParseUnmount(params utils.Params) (string, error) {
 	cmd := &UNMOUNT{}
 
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-id="[^"]+"|-id=[^\s]+`)
-	matches := re.FindAllString(args, -1)
+	cmd.id = strings.ToUpper(params.Value("id"))
+	cmd.force = params.Has("force")
 
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parámetro inválido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
-
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
-
-		switch key {
-		case "-id":
-			if value == "" {
-				return "", errors.New("el ID no puede estar vacío")
-			}
-			cmd.id = strings.ToUpper(value)
-		default:
-			return "", fmt.Errorf("parámetro desconocido: %s", key)
+	// Una sesión abierta en la partición quedaría apuntando a un ID que ya no existe
+	sessionUser := ""
+	if username, partitionID := stores.Auth.GetCurrentUser(); stores.Auth.IsAuthenticated() && strings.EqualFold(partitionID, cmd.id) {
+		if !cmd.force {
+			return "", fmt.Errorf("ERROR: el usuario '%s' tiene una sesión activa en %s; use logout o -force", username, cmd.id)
 		}
+		sessionUser = username
 	}
 
-	if cmd.id == "" {
-		return "", errors.New("faltan parámetros requeridos: -id")
+	// Desmontar la partición
+	msg, err := commandUnmount(cmd)
+	if err != nil {
+		return "", err
 	}
 
-	// Desmontar la partición
-	return commandUnmount(cmd)
+	if sessionUser != "" {
+		stores.Auth.Logout()
+		msg += fmt.Sprintf("\n-> Se cerró la sesión de '%s'", sessionUser)
+	}
+	return msg, nil
 }
 
 func commandUnmount(unmount *UNMOUNT) (string, error) {
@@ -58,10 +49,19 @@ func commandUnmount(unmount *UNMOUNT) (string, error) {
 	if !exists {
 		return "", fmt.Errorf("ERROR: no existe una partición montada con el ID: %s", unmount.id)
 	}
+	dev, err := stores.OpenDevice(diskPath)
+	if err != nil {
+		return "", fmt.Errorf("error abriendo el disco: %v", err)
+	}
+
+	// Las lógicas guardan el estado en su EBR
+	if position, ok := stores.MountedLogicals[unmount.id]; ok {
+		return unmountLogicalPartition(unmount, dev, diskPath, position)
+	}
 
 	// Leer el MBR del disco
 	var mbr structures.MBR
-	err := mbr.Deserialize(diskPath)
+	err = mbr.Deserialize(dev)
 	if err != nil {
 		return "", fmt.Errorf("error leyendo MBR: %v", err)
 	}
@@ -75,6 +75,10 @@ func commandUnmount(unmount *UNMOUNT) (string, error) {
 	// Obtener el nombre de la partición antes de desmontarla (para el mensaje)
 	partName := strings.TrimRight(string(partition.Part_name[:]), "\x00")
 
+	if err := updateMountTimes(dev, int64(partition.Part_start), false); err != nil {
+		return "", fmt.Errorf("error actualizando el superbloque: %v", err)
+	}
+
 	// Desmontar la partición (cambiar estado y resetear correlativo)
 	err = partition.UnmountPartition()
 	if err != nil {
@@ -91,7 +95,7 @@ func commandUnmount(unmount *UNMOUNT) (string, error) {
 	}
 
 	// Serializar el MBR actualizado
-	err = mbr.Serialize(diskPath)
+	err = mbr.Serialize(dev)
 	if err != nil {
 		return "", fmt.Errorf("error guardando MBR: %v", err)
 	}
@@ -104,3 +108,46 @@ func commandUnmount(unmount *UNMOUNT) (string, error) {
 		"-> Path: %s",
 		partName, unmount.id, diskPath), nil
 }
+
+// unmountLogicalPartition desmonta una lógica marcando su EBR como no montado
+func unmountLogicalPartition(unmount *UNMOUNT, dev structures.Device, diskPath string, position int64) (string, error) {
+	var ebr structures.EBR
+	if err := ebr.Deserialize(dev, int(position)); err != nil {
+		return "", fmt.Errorf("error leyendo EBR: %v", err)
+	}
+
+	partName := strings.TrimRight(string(ebr.Part_name[:]), "\x00")
+
+	if err := updateMountTimes(dev, int64(ebr.Part_start), false); err != nil {
+		return "", fmt.Errorf("error actualizando el superbloque: %v", err)
+	}
+
+	ebr.Part_mount = [1]byte{'0'}
+	if err := ebr.Serialize(dev, int(position)); err != nil {
+		return "", fmt.Errorf("error guardando EBR: %v", err)
+	}
+
+	delete(stores.MountedPartitions, unmount.id)
+	delete(stores.MountedLogicals, unmount.id)
+
+	return fmt.Sprintf("UNMOUNT: Partición '%s' desmontada exitosamente\n"+
+		"-> ID: %s\n"+
+		"-> Path: %s",
+		partName, unmount.id, diskPath), nil
+}
+
+// dropMounts quita los montajes de ids sin tocar el disco (la partición ya no existe o
+// su tabla se reescribió) y cierra la sesión si estaba en uno de ellos. Devuelve el
+// usuario cuya sesión se cerró.
+func dropMounts(ids []string) string {
+	sessionUser := ""
+	for _, id := range ids {
+		if username, partitionID := stores.Auth.GetCurrentUser(); stores.Auth.IsAuthenticated() && strings.EqualFold(partitionID, id) {
+			stores.Auth.Logout()
+			sessionUser = username
+		}
+		delete(stores.MountedPartitions, id)
+		delete(stores.MountedLogicals, id)
+	}
+	return sessionUser
+}
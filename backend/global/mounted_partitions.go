@@ -1,13 +1,11 @@
 package global
 
 import (
+	stores "backend/stores"
 	structures "backend/structures"
 	"errors"
 )
 
-// Carnet de estudiante
-const Carnet string = "39"
-
 // Estructura para guardar información completa de particiones montadas
 type MountedPartition struct {
 	Id   string
@@ -28,8 +26,13 @@ func GetMountedPartition(id string) (*structures.Partition, string, error) {
 		return nil, "", errors.New("la partición no está montada")
 	}
 
+	dev, err := stores.OpenDevice(mount.Path)
+	if err != nil {
+		return nil, "", err
+	}
+
 	var mbr structures.MBR
-	if err := mbr.Deserialize(mount.Path); err != nil {
+	if err := mbr.Deserialize(dev); err != nil {
 		return nil, "", err
 	}
 
@@ -2,8 +2,9 @@ package main
 
 import (
 	analyzer "backend/analyzer"
+	"bufio"
+	"encoding/json"
 	"fmt"
-	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -11,10 +12,22 @@ import (
 
 type CommandRequest struct {
 	Command string `json:"command"`
+	Mode    string `json:"mode"`   // "lines" agrega el detalle por línea a la respuesta
+	DryRun  bool   `json:"dryRun"` // Los comandos destructivos solo informan lo que harían
 }
 
 type CommandResponse struct {
-	Output string `json:"output"`
+	Output  string                `json:"output"`
+	Results []analyzer.LineResult `json:"results,omitempty"`
+}
+
+// Modo de respuesta con un registro por cada línea del script
+const ModeLines = "lines"
+
+// StreamSummary evento final del streaming con el total de comandos ejecutados
+type StreamSummary struct {
+	Total  int `json:"total"`
+	Errors int `json:"errors"`
 }
 
 func main() {
@@ -30,32 +43,85 @@ func main() {
 			})
 		}
 
-		commands := strings.Split(req.Command, "\n")
 		output := ""
+		var results []analyzer.LineResult
 
-		for _, cmd := range commands {
-			// Ignorar líneas vacías y comentarios
-			trimmedCmd := strings.TrimSpace(cmd)
-			if trimmedCmd == "" || strings.HasPrefix(trimmedCmd, "#") {
-				continue
+		analyzer.ExecuteScript(req.Command, analyzer.Options{DryRun: req.DryRun}, func(result analyzer.LineResult) bool {
+			switch result.Status {
+			case analyzer.StatusError:
+				output += fmt.Sprintf("Error: %s\n", result.Error)
+			case analyzer.StatusOK:
+				output += fmt.Sprintf("%s\n", result.Output)
 			}
 
-			result, err := analyzer.Analyzer(cmd)
-			if err != nil {
-				output += fmt.Sprintf("Error: %s\n", err.Error())
-			} else {
-				output += fmt.Sprintf("%s\n", result)
+			if req.Mode == ModeLines {
+				results = append(results, result)
 			}
-		}
+			return true
+		})
 
 		if output == "" {
 			output = "No se ejecutó ningún comando"
 		}
 
 		return c.JSON(CommandResponse{
-			Output: output,
+			Output:  output,
+			Results: results,
+		})
+	})
+
+	// Ejecuta el script enviando un evento SSE por cada línea apenas termina
+	app.Post("/execute/stream", func(c *fiber.Ctx) error {
+		var req CommandRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(CommandResponse{
+				Output: "Error: Petición inválida",
+			})
+		}
+
+		c.Set("Content-Type", "text/event-stream")
+		c.Set("Cache-Control", "no-cache")
+		c.Set("Connection", "keep-alive")
+
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			summary := StreamSummary{}
+
+			analyzer.ExecuteScript(req.Command, analyzer.Options{DryRun: req.DryRun}, func(result analyzer.LineResult) bool {
+				if result.Status == analyzer.StatusSkipped {
+					return true
+				}
+
+				summary.Total++
+				if result.Status == analyzer.StatusError {
+					summary.Errors++
+				}
+				// Si el cliente se desconectó no se ejecuta el resto del script
+				return writeEvent(w, "line", result) == nil
+			})
+
+			writeEvent(w, "done", summary)
 		})
+
+		return nil
+	})
+
+	// Metadatos de los comandos registrados (parámetros, valores permitidos y defaults)
+	// para la ayuda y el autocompletado del editor
+	app.Get("/commands", func(c *fiber.Ctx) error {
+		return c.JSON(analyzer.Commands())
 	})
 
 	app.Listen(":3001")
 }
+
+// writeEvent escribe un evento Server-Sent Events y lo envía de inmediato al cliente.
+// Devuelve error si no se pudo enviar (el cliente cerró la conexión).
+func writeEvent(w *bufio.Writer, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil
+	}
+
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+	return w.Flush()
+}
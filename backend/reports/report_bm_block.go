@@ -0,0 +1,39 @@
+package reports
+
+import (
+	structures "backend/structures"
+	utils "backend/utils"
+	"fmt"
+	"os"
+)
+
+// ReportBMBlock genera un reporte del bitmap de bloques y lo guarda en la ruta especificada
+func ReportBMBlock(superblock *structures.SuperBlock, dev structures.Device, path string) error {
+	// Crear las carpetas padre si no existen
+	err := utils.CreateParentDirs(path)
+	if err != nil {
+		return err
+	}
+
+	// Leer el bitmap de bloques completo
+	bitmap, err := superblock.BlockBitmap(dev).ReadAll()
+	if err != nil {
+		return fmt.Errorf("error al leer el bitmap de bloques: %v", err)
+	}
+
+	// Crear el archivo TXT
+	txtFile, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error al crear el archivo TXT: %v", err)
+	}
+	defer txtFile.Close()
+
+	// Escribir el contenido del bitmap en el archivo TXT ('0' libre, '1' en uso)
+	_, err = txtFile.WriteString(bitmapReport(bitmap))
+	if err != nil {
+		return fmt.Errorf("error al escribir en el archivo TXT: %v", err)
+	}
+
+	fmt.Println("Archivo del bitmap de bloques generado:", path)
+	return nil
+}
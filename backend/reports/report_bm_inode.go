@@ -9,48 +9,21 @@ import (
 )
 
 // ReportBMInode genera un reporte del bitmap de inodos y lo guarda en la ruta especificada
-func ReportBMInode(superblock *structures.SuperBlock, diskPath string, path string) error {
+func ReportBMInode(superblock *structures.SuperBlock, dev structures.Device, path string) error {
 	// Crear las carpetas padre si no existen
 	err := utils.CreateParentDirs(path)
 	if err != nil {
 		return err
 	}
 
-	// Abrir el archivo de disco
-	file, err := os.Open(diskPath)
+	// Leer el bitmap de inodos completo
+	bitmap, err := superblock.InodeBitmap(dev).ReadAll()
 	if err != nil {
-		return fmt.Errorf("error al abrir el archivo de disco: %v", err)
+		return fmt.Errorf("error al leer el bitmap de inodos: %v", err)
 	}
-	defer file.Close()
 
-	// Calcular el número total de inodos
-	totalInodes := superblock.S_inodes_count + superblock.S_free_inodes_count
-
-	// Obtener el contenido del bitmap de inodos
-	var bitmapContent strings.Builder
-
-	for i := int32(0); i < totalInodes; i++ {
-		// Establecer el puntero
-		_, err := file.Seek(int64(superblock.S_bm_inode_start+i), 0)
-		if err != nil {
-			return fmt.Errorf("error al establecer el puntero en el archivo: %v", err)
-		}
-
-		// Leer un byte (carácter '0' o '1')
-		char := make([]byte, 1)
-		_, err = file.Read(char)
-		if err != nil {
-			return fmt.Errorf("error al leer el byte del archivo: %v", err)
-		}
-
-		// Agregar el carácter al contenido del bitmap
-		bitmapContent.WriteByte(char[0])
-
-		// Agregar un carácter de nueva línea cada 20 caracteres (20 inodos)
-		if (i+1)%20 == 0 {
-			bitmapContent.WriteString("\n")
-		}
-	}
+	// Obtener el contenido del bitmap de inodos ('0' libre, '1' en uso)
+	content := bitmapReport(bitmap)
 
 	// Crear el archivo TXT
 	txtFile, err := os.Create(path)
@@ -60,7 +33,7 @@ func ReportBMInode(superblock *structures.SuperBlock, diskPath string, path stri
 	defer txtFile.Close()
 
 	// Escribir el contenido del bitmap en el archivo TXT
-	_, err = txtFile.WriteString(bitmapContent.String())
+	_, err = txtFile.WriteString(content)
 	if err != nil {
 		return fmt.Errorf("error al escribir en el archivo TXT: %v", err)
 	}
@@ -68,3 +41,21 @@ func ReportBMInode(superblock *structures.SuperBlock, diskPath string, path stri
 	fmt.Println("Archivo del bitmap de inodos generado:", path)
 	return nil
 }
+
+// bitmapReport texto del bitmap con un carácter '0' o '1' por objeto, 20 por línea
+func bitmapReport(bitmap []bool) string {
+	var b strings.Builder
+	for i, used := range bitmap {
+		if used {
+			b.WriteByte('1')
+		} else {
+			b.WriteByte('0')
+		}
+
+		// Agregar un carácter de nueva línea cada 20 caracteres
+		if (i+1)%20 == 0 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
@@ -10,7 +10,7 @@ import (
 )
 
 // ReportInode genera un reporte de un inodo y lo guarda en la ruta especificada
-func ReportInode(superblock *structures.SuperBlock, diskPath string, path string) error {
+func ReportInode(superblock *structures.SuperBlock, dev structures.Device, path string) error {
 	// Crear las carpetas padre si no existen
 	err := utils.CreateParentDirs(path)
 	if err != nil {
@@ -29,15 +29,15 @@ func ReportInode(superblock *structures.SuperBlock, diskPath string, path string
 	for i := int32(0); i < superblock.S_inodes_count; i++ {
 		inode := &structures.Inode{}
 		// Deserializar el inodo
-		err := inode.Deserialize(diskPath, int64(superblock.S_inode_start+(i*superblock.S_inode_size)))
+		err := inode.Deserialize(dev, superblock.InodeOffset(int64(i)))
 		if err != nil {
 			return err
 		}
 
 		// Convertir tiempos a string
-		atime := time.Unix(int64(inode.I_atime), 0).Format(time.RFC3339)
-		ctime := time.Unix(int64(inode.I_ctime), 0).Format(time.RFC3339)
-		mtime := time.Unix(int64(inode.I_mtime), 0).Format(time.RFC3339)
+		atime := time.Unix(0, inode.I_atime).Format(time.RFC3339)
+		ctime := time.Unix(0, inode.I_ctime).Format(time.RFC3339)
+		mtime := time.Unix(0, inode.I_mtime).Format(time.RFC3339)
 
 		// Definir el contenido DOT para el inodo actual
 		dotContent += fmt.Sprintf(`inode%d [label=<
@@ -30,7 +30,7 @@ func ReportMBR(mbr *structures.MBR, path string) error {
                 <tr><td>mbr_tamano</td><td>%d</td></tr>
                 <tr><td>mrb_fecha_creacion</td><td>%s</td></tr>
                 <tr><td>mbr_disk_signature</td><td>%d</td></tr>
-            `, mbr.Mbr_size, time.Unix(int64(mbr.Mbr_creation_date), 0), mbr.Mbr_disk_signature)
+            `, mbr.Mbr_size, time.Unix(0, mbr.Mbr_creation_date), mbr.Mbr_disk_signature)
 
 	// Agregar las particiones a la tabla
 	for i, part := range mbr.Mbr_partitions {
@@ -0,0 +1,141 @@
+package stores
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Prefijo de los IDs si no se configura MIA_ID_PREFIX (últimos dígitos del carnet 202201139)
+	defaultIDPrefix = "39"
+	// Megabytes que pueden sumar los discos en memoria si no se configura MIA_MEM_LIMIT_MB
+	defaultMemLimitMB = 256
+	// Raíz de los discos si no se configura MIA_DISK_ROOT, relativa a la carpeta del backend
+	defaultDiskRoot = "disks"
+)
+
+// ConfigStore configuración global del backend, leída de variables de entorno al iniciar
+type ConfigStore struct {
+	DryRun      bool   // MIA_DRY_RUN: los comandos destructivos solo informan lo que harían
+	DiskRoot    string // MIA_DISK_ROOT: carpeta de la que no pueden salir los discos y reportes ("" = sin límite, solo con MIA_NO_DISK_ROOT)
+	IDPrefix    string // MIA_ID_PREFIX: prefijo de los IDs de las particiones montadas
+	LettersFile string // MIA_LETTERS_FILE: archivo con la letra asignada a cada disco
+	MemLimit    int64  // MIA_MEM_LIMIT_MB: bytes que pueden sumar los discos en memoria
+}
+
+var Config = loadConfig()
+
+func loadConfig() *ConfigStore {
+	config := &ConfigStore{
+		DryRun:   envBool("MIA_DRY_RUN"),
+		IDPrefix: strings.ToUpper(envString("MIA_ID_PREFIX", defaultIDPrefix)),
+		MemLimit: envInt("MIA_MEM_LIMIT_MB", defaultMemLimitMB) * 1024 * 1024,
+	}
+	// Sin raíz los comandos aceptan cualquier ruta del servidor: hay que pedirlo explícitamente
+	if !envBool("MIA_NO_DISK_ROOT") {
+		root := envString("MIA_DISK_ROOT", defaultDiskRoot)
+		if abs, err := filepath.Abs(root); err == nil {
+			root = abs
+		}
+		config.DiskRoot = filepath.Clean(root)
+	}
+
+	// Por defecto las letras se guardan junto a los discos
+	config.LettersFile = envString("MIA_LETTERS_FILE", filepath.Join(config.DiskRoot, "mia_letters.json"))
+	return config
+}
+
+// envBool interpreta 1, true, yes o si como verdadero
+func envBool(name string) bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(name))) {
+	case "1", "true", "yes", "si", "sí":
+		return true
+	}
+	return false
+}
+
+// envString devuelve la variable de entorno o def si no está definida
+func envString(name, def string) string {
+	if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+		return value
+	}
+	return def
+}
+
+// envInt devuelve la variable de entorno como entero no negativo, o def si no está
+// definida o no es válida
+func envInt(name string, def int64) int64 {
+	value, err := strconv.ParseInt(strings.TrimSpace(os.Getenv(name)), 10, 64)
+	if err != nil || value < 0 {
+		return def
+	}
+	return value
+}
+
+// ResolveDiskPath ubica una ruta de disco o de reporte dentro de Config.DiskRoot. Las
+// rutas se toman relativas a la raíz (/home/user/d.mia -> <raíz>/home/user/d.mia), así
+// los scripts existentes funcionan sin cambios; ni ".." ni los enlaces simbólicos
+// permiten salir de ella. Con MIA_NO_DISK_ROOT la ruta solo se limpia.
+func ResolveDiskPath(path string) (string, error) {
+	root := Config.DiskRoot
+	if root == "" {
+		return filepath.Clean(path), nil
+	}
+
+	// Limpiar como ruta absoluta descarta los ".." que intentan subir de la raíz
+	return confineToRoot(path, filepath.Join(root, filepath.Clean("/"+path)))
+}
+
+// ResolveDiskPathFrom como ResolveDiskPath, pero una ruta relativa parte de dir (una
+// ruta ya resuelta, como la carpeta de un script) en lugar de la raíz
+func ResolveDiskPathFrom(dir, path string) (string, error) {
+	if filepath.IsAbs(path) {
+		return ResolveDiskPath(path)
+	}
+	resolved := filepath.Join(dir, path)
+	if Config.DiskRoot == "" {
+		return resolved, nil
+	}
+	return confineToRoot(path, resolved)
+}
+
+// confineToRoot valida que resolved no salga de la raíz, ni con ".." ni con enlaces
+// simbólicos; path es la ruta original para los mensajes de error
+func confineToRoot(path, resolved string) (string, error) {
+	root := Config.DiskRoot
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return "", fmt.Errorf("no se pudo crear la raíz de discos %s: %v", root, err)
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", fmt.Errorf("raíz de discos inválida %s: %v", root, err)
+	}
+
+	// Un enlace simbólico dentro de la raíz podría apuntar afuera: se valida la
+	// parte de la ruta que ya existe con los enlaces resueltos
+	existing, rest := resolved, ""
+	for {
+		real, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			if !isWithin(realRoot, real) {
+				return "", fmt.Errorf("la ruta %s sale de la raíz de discos", path)
+			}
+			return filepath.Join(real, rest), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("ruta inválida %s: %v", path, err)
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = filepath.Dir(existing)
+	}
+}
+
+// isWithin indica si path es root o está dentro de root
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,145 @@
+package stores
+
+import (
+	structures "backend/structures"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// openDevice disco abierto junto con el archivo que tenía al abrirse
+type openDevice struct {
+	device structures.Device
+	info   os.FileInfo // nil en los discos en memoria
+}
+
+var (
+	devicesMu sync.Mutex
+	devices   = make(map[string]*openDevice)
+)
+
+// OpenDevice devuelve el disco de path. Se abre la primera vez que se usa y queda
+// abierto (con su caché) para los comandos siguientes; si el archivo se eliminó o se
+// reemplazó por fuera se vuelve a abrir.
+func OpenDevice(path string) (structures.Device, error) {
+	key := filepath.Clean(path)
+
+	devicesMu.Lock()
+	defer devicesMu.Unlock()
+
+	// Los discos en memoria no tienen archivo que revisar
+	if open, ok := devices[key]; ok && open.info == nil {
+		return open.device, nil
+	}
+
+	info, err := os.Stat(key)
+	if err != nil {
+		dropDevice(key)
+		return nil, err
+	}
+	if open, ok := devices[key]; ok {
+		if os.SameFile(open.info, info) {
+			return open.device, nil
+		}
+		dropDevice(key)
+	}
+
+	device, err := structures.OpenBlockDevice(key)
+	if err != nil {
+		return nil, err
+	}
+	devices[key] = &openDevice{device: device, info: info}
+	return device, nil
+}
+
+// CreateMemDevice crea un disco en memoria de size bytes en path. La ruta solo sirve
+// para nombrarlo en los comandos; no se crea ningún archivo. Entre todos los discos en
+// memoria no pueden pasar de Config.MemLimit bytes.
+func CreateMemDevice(path string, size int64) (structures.Device, error) {
+	key := filepath.Clean(path)
+
+	devicesMu.Lock()
+	defer devicesMu.Unlock()
+
+	if open, ok := devices[key]; ok && open.info == nil {
+		return nil, fmt.Errorf("ya existe un disco en memoria en la ruta %s", path)
+	}
+	if _, err := os.Stat(key); err == nil {
+		return nil, fmt.Errorf("ya existe un disco en la ruta %s", path)
+	}
+
+	// Los discos en memoria ocupan toda su RAM desde que se crean: se limita el total
+	used := int64(0)
+	for _, open := range devices {
+		if open.info == nil {
+			used += open.device.Size()
+		}
+	}
+	if size > Config.MemLimit-used {
+		return nil, fmt.Errorf("el disco de %d bytes supera el límite de discos en memoria (%d bytes usados de %d, MIA_MEM_LIMIT_MB)",
+			size, used, Config.MemLimit)
+	}
+
+	device := structures.NewMemDevice(size)
+	devices[key] = &openDevice{device: device}
+	return device, nil
+}
+
+// IsMemDevice indica si path es un disco en memoria
+func IsMemDevice(path string) bool {
+	devicesMu.Lock()
+	defer devicesMu.Unlock()
+
+	open, ok := devices[filepath.Clean(path)]
+	return ok && open.info == nil
+}
+
+// RemoveDisk cierra el disco y elimina su archivo; un disco en memoria simplemente se descarta
+func RemoveDisk(path string) error {
+	memory := IsMemDevice(path)
+	if err := CloseDevice(path); err != nil {
+		fmt.Println("ADVERTENCIA:", err)
+	}
+	if memory {
+		return nil
+	}
+	return os.Remove(path)
+}
+
+// FlushDevice escribe en el archivo los cambios pendientes del disco de path, si está
+// abierto. Se llama con el lock del disco tomado.
+func FlushDevice(path string) error {
+	devicesMu.Lock()
+	defer devicesMu.Unlock()
+
+	open, ok := devices[filepath.Clean(path)]
+	if !ok {
+		return nil
+	}
+	return open.device.Flush()
+}
+
+// CloseDevice guarda y cierra el disco de path; se usa antes de eliminar o reemplazar el
+// archivo. Cerrar un disco en memoria lo elimina.
+func CloseDevice(path string) error {
+	key := filepath.Clean(path)
+
+	devicesMu.Lock()
+	defer devicesMu.Unlock()
+
+	open, ok := devices[key]
+	if !ok {
+		return nil
+	}
+	delete(devices, key)
+	return open.device.Close()
+}
+
+// dropDevice cierra un disco cuyo archivo ya no existe; lo pendiente se descarta
+func dropDevice(key string) {
+	if open, ok := devices[key]; ok {
+		_ = open.device.Close()
+		delete(devices, key)
+	}
+}
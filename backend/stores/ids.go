@@ -0,0 +1,142 @@
+package stores
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+	// Tamaño de Part_id en el MBR: el ID completo debe caber ahí
+	maxPartitionIDLength = 4
+)
+
+// diskLetters letra asignada a cada disco, por Mbr_disk_signature. Se guarda en
+// Config.LettersFile para que un disco conserve su letra entre reinicios y aunque
+// se renombre el archivo.
+var diskLetters = loadDiskLetters()
+
+// memoryLetters firmas de los discos en memoria: su letra no se guarda en el archivo
+var memoryLetters = map[string]bool{}
+
+type lettersFile struct {
+	Letters map[string]string `json:"letters"` // Firma del disco -> letra
+}
+
+// AllocatePartitionID genera el ID de una partición a montar: prefijo + correlativo +
+// letra del disco. El correlativo es el menor que no usa otra partición montada, así
+// los IDs se reutilizan al desmontar. Se revisan los montajes de todos los discos: una
+// copia del archivo tiene la misma firma, y por lo tanto la misma letra.
+func AllocatePartitionID(diskPath string, signature int32) (string, int, error) {
+	letter, err := diskLetter(signature, IsMemDevice(diskPath))
+	if err != nil {
+		return "", 0, err
+	}
+
+	used := map[string]bool{}
+	for id := range MountedPartitions {
+		used[id] = true
+	}
+
+	for correlative := 1; ; correlative++ {
+		id := fmt.Sprintf("%s%d%s", Config.IDPrefix, correlative, letter)
+		if len(id) > maxPartitionIDLength {
+			return "", 0, fmt.Errorf("el ID %s no cabe en Part_id (%d bytes); use un prefijo más corto en MIA_ID_PREFIX", id, maxPartitionIDLength)
+		}
+		if !used[id] {
+			return id, correlative, nil
+		}
+	}
+}
+
+// ReleaseDiskLetter libera la letra de un disco eliminado para que otro la pueda usar
+func ReleaseDiskLetter(signature int32) error {
+	key := strconv.Itoa(int(signature))
+	if _, ok := diskLetters[key]; !ok {
+		return nil
+	}
+	delete(diskLetters, key)
+	if memoryLetters[key] {
+		delete(memoryLetters, key)
+		return nil
+	}
+	return saveDiskLetters()
+}
+
+// diskLetter devuelve la letra del disco, asignando la primera libre si aún no tiene
+func diskLetter(signature int32, memory bool) (string, error) {
+	key := strconv.Itoa(int(signature))
+	if letter, ok := diskLetters[key]; ok {
+		return letter, nil
+	}
+
+	taken := map[string]bool{}
+	for _, letter := range diskLetters {
+		taken[letter] = true
+	}
+	for _, r := range alphabet {
+		letter := string(r)
+		if taken[letter] {
+			continue
+		}
+		diskLetters[key] = letter
+		if memory {
+			memoryLetters[key] = true
+			return letter, nil
+		}
+		if err := saveDiskLetters(); err != nil {
+			delete(diskLetters, key)
+			return "", err
+		}
+		return letter, nil
+	}
+	return "", fmt.Errorf("no hay más letras disponibles para asignar (%d discos registrados)", len(diskLetters))
+}
+
+func loadDiskLetters() map[string]string {
+	letters := map[string]string{}
+	data, err := os.ReadFile(Config.LettersFile)
+	if err != nil {
+		return letters
+	}
+
+	var file lettersFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		fmt.Printf("ADVERTENCIA: %s está dañado, se ignora: %v\n", Config.LettersFile, err)
+		return letters
+	}
+	for signature, letter := range file.Letters {
+		letters[signature] = strings.ToUpper(letter)
+	}
+	return letters
+}
+
+func saveDiskLetters() error {
+	persisted := map[string]string{}
+	for key, letter := range diskLetters {
+		if !memoryLetters[key] {
+			persisted[key] = letter
+		}
+	}
+	data, err := json.MarshalIndent(lettersFile{Letters: persisted}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(Config.LettersFile); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("no se pudo guardar la asignación de letras: %v", err)
+		}
+	}
+
+	// Escribir en un temporal y renombrar para no dejar el archivo a medias
+	tmp := Config.LettersFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("no se pudo guardar la asignación de letras: %v", err)
+	}
+	return os.Rename(tmp, Config.LettersFile)
+}
@@ -0,0 +1,36 @@
+package stores
+
+import (
+	"path/filepath"
+	"sync"
+)
+
+// StateLock protege el estado global compartido entre peticiones HTTP:
+// MountedPartitions, la sesión (Auth) y la asignación de letras de los IDs.
+// Los comandos que lo modifican (mount, unmount, login, logout, rmdisk, fdisk) toman
+// el lock de escritura; el resto toma el de lectura mientras se ejecuta.
+var StateLock sync.RWMutex
+
+var (
+	diskLocksMu sync.Mutex
+	diskLocks   = make(map[string]*sync.RWMutex)
+)
+
+// DiskLock devuelve el lock de lectura/escritura asociado a un disco.
+// Debe tomarse siempre después de StateLock para evitar interbloqueos.
+func DiskLock(path string) *sync.RWMutex {
+	key := filepath.Clean(path)
+	if abs, err := filepath.Abs(key); err == nil {
+		key = abs
+	}
+
+	diskLocksMu.Lock()
+	defer diskLocksMu.Unlock()
+
+	lock, ok := diskLocks[key]
+	if !ok {
+		lock = &sync.RWMutex{}
+		diskLocks[key] = lock
+	}
+	return lock
+}
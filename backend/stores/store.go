@@ -3,18 +3,34 @@ package stores
 import (
 	structures "backend/structures"
 	"errors"
-	"fmt"
 	"strings"
 )
 
-// Carnet de estudiante
-const Carnet string = "39" // 202201139
-
 // Declaración de variables globales
 var (
 	MountedPartitions map[string]string = make(map[string]string)
+	// MountedLogicals posición del EBR de cada lógica montada; el EBR no guarda el ID
+	MountedLogicals map[string]int64 = make(map[string]int64)
 )
 
+// findMountedPartition busca la partición montada con el id en el MBR o, si es una
+// lógica, en su EBR
+func findMountedPartition(mbr *structures.MBR, dev structures.Device, id string) (*structures.Partition, error) {
+	position, ok := MountedLogicals[id]
+	if !ok {
+		return mbr.GetPartitionByID(id)
+	}
+
+	var ebr structures.EBR
+	if err := ebr.Deserialize(dev, int(position)); err != nil {
+		return nil, err
+	}
+	if ebr.IsEmpty() {
+		return nil, errors.New("la partición lógica ya no existe")
+	}
+	return ebr.ToPartition(id), nil
+}
+
 func GetMountedPartition(id string) (*structures.Partition, string, error) {
 	// Normalizar id
 	id = strings.ToUpper(strings.TrimSpace(id))
@@ -25,13 +41,18 @@ func GetMountedPartition(id string) (*structures.Partition, string, error) {
 		return nil, "", errors.New("la partición no está montada")
 	}
 
+	dev, err := OpenDevice(path)
+	if err != nil {
+		return nil, "", err
+	}
+
 	var mbr structures.MBR
-	err := mbr.Deserialize(path)
+	err = mbr.Deserialize(dev)
 	if err != nil {
 		return nil, "", err
 	}
 
-	partition, err := mbr.GetPartitionByID(id)
+	partition, err := findMountedPartition(&mbr, dev, id)
 	if partition == nil {
 		return nil, "", err
 	}
@@ -48,19 +69,24 @@ func GetMountedPartitionRep(id string) (*structures.MBR, *structures.SuperBlock,
 		return nil, nil, "", errors.New("la partición no está montada")
 	}
 
+	dev, err := OpenDevice(path)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
 	var mbr structures.MBR
-	err := mbr.Deserialize(path)
+	err = mbr.Deserialize(dev)
 	if err != nil {
 		return nil, nil, "", err
 	}
 
-	partition, err := mbr.GetPartitionByID(id)
+	partition, err := findMountedPartition(&mbr, dev, id)
 	if partition == nil {
 		return nil, nil, "", err
 	}
 
 	var sb structures.SuperBlock
-	err = sb.Deserialize(path, int64(partition.Part_start))
+	err = sb.Deserialize(dev, partition.Part_start)
 	if err != nil {
 		return nil, nil, "", err
 	}
@@ -72,27 +98,29 @@ func GetMountedPartitionSuperblock(id string) (*structures.SuperBlock, *structur
 	// Normalizar id
 	id = strings.ToUpper(strings.TrimSpace(id))
 
-	fmt.Println("DEBUG -> Buscando id:", id)
-	fmt.Println("DEBUG -> MountedPartitions actuales:", MountedPartitions)
-
 	path := MountedPartitions[id]
 	if path == "" {
 		return nil, nil, "", errors.New("la partición no está montada")
 	}
 
+	dev, err := OpenDevice(path)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
 	var mbr structures.MBR
-	err := mbr.Deserialize(path)
+	err = mbr.Deserialize(dev)
 	if err != nil {
 		return nil, nil, "", err
 	}
 
-	partition, err := mbr.GetPartitionByID(id)
+	partition, err := findMountedPartition(&mbr, dev, id)
 	if partition == nil {
 		return nil, nil, "", err
 	}
 
 	var sb structures.SuperBlock
-	err = sb.Deserialize(path, int64(partition.Part_start))
+	err = sb.Deserialize(dev, partition.Part_start)
 	if err != nil {
 		return nil, nil, "", err
 	}
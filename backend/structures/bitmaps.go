@@ -1,104 +1,201 @@
 package structures
 
 import (
-	"encoding/binary"
-	"os"
+	"errors"
+	"fmt"
 )
 
-// CreateBitMaps crea los Bitmaps de inodos y bloques en el archivo especificado
-func (sb *SuperBlock) CreateBitMaps(path string) error {
-	// Escribir Bitmaps
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
-	if err != nil {
-		return err
+// FeaturePackedBitmaps bandera de S_features: los bitmaps usan un bit por inodo o
+// bloque en lugar de un byte
+const FeaturePackedBitmaps int32 = 1 << 0
+
+// BitmapSize bytes que ocupa un bitmap de count inodos o bloques
+func BitmapSize(count int64, packed bool) int64 {
+	if packed {
+		return (count + 7) / 8
 	}
-	defer file.Close()
+	return count
+}
 
-	// Bitmap de inodos
-	// Mover el puntero del archivo a la posición especificada
-	_, err = file.Seek(int64(sb.S_bm_inode_start), 0)
-	if err != nil {
-		return err
+// PackedBitmaps indica si el sistema de archivos usa bitmaps de un bit por objeto
+func (sb *SuperBlock) PackedBitmaps() bool {
+	return sb.S_features&FeaturePackedBitmaps != 0
+}
+
+// InodeCapacity cantidad de inodos de la tabla (los bloques son el triple). Sale de la
+// distribución porque S_inodes_count cambia al crear inodos.
+func (sb *SuperBlock) InodeCapacity() int64 {
+	if sb.S_inode_size <= 0 {
+		return 0
 	}
+	return (sb.S_block_start - sb.S_inode_start) / int64(sb.S_inode_size)
+}
+
+// InodeBitmap bitmap de inodos del sistema de archivos
+func (sb *SuperBlock) InodeBitmap(dev Device) *Bitmap {
+	return &Bitmap{dev: dev, start: sb.S_bm_inode_start, count: sb.InodeCapacity(), packed: sb.PackedBitmaps()}
+}
+
+// BlockBitmap bitmap de bloques del sistema de archivos
+func (sb *SuperBlock) BlockBitmap(dev Device) *Bitmap {
+	return &Bitmap{dev: dev, start: sb.S_bm_block_start, count: 3 * sb.InodeCapacity(), packed: sb.PackedBitmaps()}
+}
+
+// Bitmap bitmap de inodos o de bloques en el disco. Con un byte por objeto se escribe
+// 1/0; al leer, 0, '0' y 'O' se toman como libres porque las versiones anteriores
+// escribían '0'/'1' y 'O'/'X'. Con un bit por objeto el bit 0 de cada byte es el
+// primer objeto.
+type Bitmap struct {
+	dev    Device
+	start  int64
+	count  int64
+	packed bool
+}
 
-	// Crear un buffer de n '0'
-	buffer := make([]byte, sb.S_free_inodes_count)
-	for i := range buffer {
-		buffer[i] = '0'
+// Count cantidad de objetos del bitmap
+func (b *Bitmap) Count() int64 {
+	return b.count
+}
+
+// Used indica si el objeto index está en uso
+func (b *Bitmap) Used(index int64) (bool, error) {
+	if index < 0 || index >= b.count {
+		return false, fmt.Errorf("índice %d fuera del bitmap (%d)", index, b.count)
 	}
+	var value [1]byte
+	if _, err := b.dev.ReadAt(value[:], b.offset(index)); err != nil {
+		return false, err
+	}
+	return b.decode(value[0], index), nil
+}
 
-	// Escribir el buffer en el archivo
-	err = binary.Write(file, binary.LittleEndian, buffer)
-	if err != nil {
-		return err
+// Set marca el objeto index como usado o libre
+func (b *Bitmap) Set(index int64, used bool) error {
+	if index < 0 || index >= b.count {
+		return fmt.Errorf("índice %d fuera del bitmap (%d)", index, b.count)
 	}
+	return b.set(index, used)
+}
 
-	// Bitmap de bloques
-	// Mover el puntero del archivo a la posición especificada
-	_, err = file.Seek(int64(sb.S_bm_block_start), 0)
-	if err != nil {
+// set escribe el objeto index sin validar que esté dentro del bitmap
+func (b *Bitmap) set(index int64, used bool) error {
+	offset := b.offset(index)
+	if !b.packed {
+		value := byte(0)
+		if used {
+			value = 1
+		}
+		_, err := b.dev.WriteAt([]byte{value}, offset)
 		return err
 	}
 
-	// Crear un buffer de n 'O'
-	buffer = make([]byte, sb.S_free_blocks_count)
-	for i := range buffer {
-		buffer[i] = 'O'
+	var value [1]byte
+	if _, err := b.dev.ReadAt(value[:], offset); err != nil {
+		return err
 	}
+	if used {
+		value[0] |= 1 << (index % 8)
+	} else {
+		value[0] &^= 1 << (index % 8)
+	}
+	_, err := b.dev.WriteAt(value[:], offset)
+	return err
+}
 
-	// Escribir el buffer en el archivo
-	err = binary.Write(file, binary.LittleEndian, buffer)
-	if err != nil {
-		return err
+// ReadAll devuelve el estado de todos los objetos del bitmap
+func (b *Bitmap) ReadAll() ([]bool, error) {
+	raw := make([]byte, BitmapSize(b.count, b.packed))
+	if _, err := b.dev.ReadAt(raw, b.start); err != nil {
+		return nil, err
 	}
 
-	return nil
+	used := make([]bool, b.count)
+	for i := range used {
+		if b.packed {
+			used[i] = b.decode(raw[i/8], int64(i))
+		} else {
+			used[i] = b.decode(raw[i], int64(i))
+		}
+	}
+	return used, nil
 }
 
-// Actualizar Bitmap de inodos
-func (sb *SuperBlock) UpdateBitmapInode(path string) error {
-	// Abrir el archivo
-	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+// FirstFree índice del primer objeto libre
+func (b *Bitmap) FirstFree() (int64, error) {
+	used, err := b.ReadAll()
 	if err != nil {
-		return err
+		return -1, err
 	}
-	defer file.Close()
-
-	// Mover el puntero del archivo a la posición del bitmap de inodos
-	_, err = file.Seek(int64(sb.S_bm_inode_start)+int64(sb.S_inodes_count), 0)
-	if err != nil {
-		return err
+	for i, u := range used {
+		if !u {
+			return int64(i), nil
+		}
 	}
+	return -1, errors.New("no hay inodos o bloques libres en el bitmap")
+}
 
-	// Escribir el bit en el archivo
-	_, err = file.Write([]byte{'1'})
-	if err != nil {
+// Reset deja todo el bitmap libre salvo los objetos indicados
+func (b *Bitmap) Reset(used ...int64) error {
+	if _, err := b.dev.WriteAt(make([]byte, BitmapSize(b.count, b.packed)), b.start); err != nil {
 		return err
 	}
-
+	for _, index := range used {
+		if err := b.Set(index, true); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// Actualizar Bitmap de bloques
-func (sb *SuperBlock) UpdateBitmapBlock(path string) error {
-	// Abrir el archivo
-	file, err := os.OpenFile(path, os.O_RDWR, 0644)
-	if err != nil {
-		return err
+// ClearFrom marca como libres los objetos desde from hasta el final del bitmap
+func (b *Bitmap) ClearFrom(from int64) error {
+	if from >= b.count {
+		return nil
+	}
+	// Los objetos que comparten byte con los anteriores se limpian uno por uno
+	for ; b.packed && from%8 != 0 && from < b.count; from++ {
+		if err := b.set(from, false); err != nil {
+			return err
+		}
+	}
+	if from >= b.count {
+		return nil
 	}
-	defer file.Close()
+	start := b.offset(from)
+	_, err := b.dev.WriteAt(make([]byte, b.start+BitmapSize(b.count, b.packed)-start), start)
+	return err
+}
 
-	// Mover el puntero del archivo a la posición del bitmap de bloques
-	_, err = file.Seek(int64(sb.S_bm_block_start)+int64(sb.S_blocks_count), 0)
-	if err != nil {
-		return err
+func (b *Bitmap) offset(index int64) int64 {
+	if b.packed {
+		return b.start + index/8
 	}
+	return b.start + index
+}
 
-	// Escribir el bit en el archivo
-	_, err = file.Write([]byte{'X'})
-	if err != nil {
+func (b *Bitmap) decode(value byte, index int64) bool {
+	if b.packed {
+		return value&(1<<(index%8)) != 0
+	}
+	return value != 0 && value != '0' && value != 'O'
+}
+
+// CreateBitMaps crea los Bitmaps de inodos y bloques en el disco, todos libres
+func (sb *SuperBlock) CreateBitMaps(dev Device) error {
+	if err := sb.InodeBitmap(dev).Reset(); err != nil {
 		return err
 	}
+	return sb.BlockBitmap(dev).Reset()
+}
 
-	return nil
+// Actualizar Bitmap de inodos
+func (sb *SuperBlock) UpdateBitmapInode(dev Device) error {
+	// Marcar el inodo en la posición del bitmap de inodos
+	return sb.InodeBitmap(dev).set(int64(sb.S_inodes_count), true)
+}
+
+// Actualizar Bitmap de bloques
+func (sb *SuperBlock) UpdateBitmapBlock(dev Device) error {
+	// Marcar el bloque en la posición del bitmap de bloques
+	return sb.BlockBitmap(dev).set(int64(sb.S_blocks_count), true)
 }
@@ -0,0 +1,225 @@
+package structures
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Device disco sobre el que se leen y escriben las estructuras. Las escrituras pueden
+// quedar en memoria hasta Flush.
+type Device interface {
+	io.ReaderAt
+	io.WriterAt
+	Size() int64
+	Flush() error
+	Close() error
+}
+
+const (
+	// Tamaño de las páginas del caché: caben 32 inodos o 64 bloques
+	devicePageSize = 4096
+	// Páginas en memoria antes de vaciar el caché (16 MB)
+	deviceMaxPages = 4096
+	// Lecturas y escrituras más grandes van directo al archivo en las páginas que no
+	// están en caché (bitmaps completos, ceros de delete=full, copias de resize)
+	deviceDirectThreshold = 64 * 1024
+)
+
+type devicePage struct {
+	data  []byte
+	dirty bool
+}
+
+// BlockDevice disco respaldado por un archivo que se mantiene abierto, con un caché
+// write-back por páginas: los inodos y bloques que se leen o escriben varias veces en
+// un comando se quedan en memoria y los cambios se escriben juntos en Flush.
+type BlockDevice struct {
+	mu    sync.Mutex
+	file  *os.File
+	size  int64
+	pages map[int64]*devicePage // Número de página -> contenido
+}
+
+// OpenBlockDevice abre el disco en path para lectura y escritura
+func OpenBlockDevice(path string) (*BlockDevice, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &BlockDevice{file: file, size: info.Size(), pages: make(map[int64]*devicePage)}, nil
+}
+
+// Size tamaño del disco en bytes, incluidas las escrituras pendientes
+func (d *BlockDevice) Size() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.size
+}
+
+func (d *BlockDevice) ReadAt(p []byte, off int64) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if off < 0 {
+		return 0, errors.New("offset negativo")
+	}
+	if off >= d.size {
+		return 0, io.EOF
+	}
+
+	// Como os.File: lo que pasa del final del disco devuelve io.EOF
+	n := len(p)
+	if off+int64(n) > d.size {
+		n = int(d.size - off)
+	}
+	direct := n > deviceDirectThreshold
+
+	for done := 0; done < n; {
+		pageNum := (off + int64(done)) / devicePageSize
+		pageOff := int((off + int64(done)) % devicePageSize)
+		chunk := min(n-done, devicePageSize-pageOff)
+
+		page, ok := d.pages[pageNum]
+		if !ok && direct {
+			clear(p[done : done+chunk])
+			if _, err := d.file.ReadAt(p[done:done+chunk], off+int64(done)); err != nil && !errors.Is(err, io.EOF) {
+				return done, err
+			}
+		} else {
+			if !ok {
+				var err error
+				if page, err = d.loadPage(pageNum); err != nil {
+					return done, err
+				}
+			}
+			copy(p[done:done+chunk], page.data[pageOff:])
+		}
+		done += chunk
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (d *BlockDevice) WriteAt(p []byte, off int64) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if off < 0 {
+		return 0, errors.New("offset negativo")
+	}
+	direct := len(p) > deviceDirectThreshold
+
+	for done := 0; done < len(p); {
+		pageNum := (off + int64(done)) / devicePageSize
+		pageOff := int((off + int64(done)) % devicePageSize)
+		chunk := min(len(p)-done, devicePageSize-pageOff)
+
+		page, ok := d.pages[pageNum]
+		if !ok && direct && chunk == devicePageSize {
+			// Página completa que no está en caché: se escribe directo
+			if _, err := d.file.WriteAt(p[done:done+chunk], off+int64(done)); err != nil {
+				return done, err
+			}
+		} else {
+			if !ok {
+				var err error
+				if page, err = d.loadPage(pageNum); err != nil {
+					return done, err
+				}
+			}
+			copy(page.data[pageOff:], p[done:done+chunk])
+			page.dirty = true
+		}
+		done += chunk
+	}
+
+	d.size = max(d.size, off+int64(len(p)))
+	return len(p), nil
+}
+
+// loadPage lee una página del archivo y la agrega al caché
+func (d *BlockDevice) loadPage(pageNum int64) (*devicePage, error) {
+	if len(d.pages) >= deviceMaxPages {
+		if err := d.flushLocked(); err != nil {
+			return nil, err
+		}
+		clear(d.pages)
+	}
+
+	page := &devicePage{data: make([]byte, devicePageSize)}
+	if _, err := d.file.ReadAt(page.data, pageNum*devicePageSize); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	d.pages[pageNum] = page
+	return page, nil
+}
+
+// Flush escribe en el archivo las páginas modificadas
+func (d *BlockDevice) Flush() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.flushLocked()
+}
+
+func (d *BlockDevice) flushLocked() error {
+	var dirty []int64
+	for pageNum, page := range d.pages {
+		if page.dirty {
+			dirty = append(dirty, pageNum)
+		}
+	}
+	sort.Slice(dirty, func(a, b int) bool { return dirty[a] < dirty[b] })
+
+	for _, pageNum := range dirty {
+		page := d.pages[pageNum]
+		// La última página puede pasar del final del disco
+		length := min(int64(devicePageSize), d.size-pageNum*devicePageSize)
+		if length <= 0 {
+			continue
+		}
+		if _, err := d.file.WriteAt(page.data[:length], pageNum*devicePageSize); err != nil {
+			return err
+		}
+		page.dirty = false
+	}
+	return nil
+}
+
+// Close escribe los cambios pendientes y cierra el archivo
+func (d *BlockDevice) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	err := d.flushLocked()
+	if closeErr := d.file.Close(); err == nil {
+		err = closeErr
+	}
+	clear(d.pages)
+	return err
+}
+
+// writeStruct serializa data en offset
+func writeStruct(dev Device, offset int64, data any) error {
+	return binary.Write(io.NewOffsetWriter(dev, offset), binary.LittleEndian, data)
+}
+
+// readStruct deserializa data desde offset
+func readStruct(dev Device, offset int64, data any) error {
+	size := binary.Size(data)
+	if size <= 0 {
+		return errors.New("estructura de tamaño inválido")
+	}
+	return binary.Read(io.NewSectionReader(dev, offset, int64(size)), binary.LittleEndian, data)
+}
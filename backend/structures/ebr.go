@@ -1,10 +1,7 @@
 package structures
 
 import (
-	"bytes"
-	"encoding/binary"
 	"fmt"
-	"os"
 )
 
 // EBR (Extended Boot Record) - Descriptor de partición lógica
@@ -12,9 +9,9 @@ import (
 type EBR struct {
 	Part_mount [1]byte  // Indica si la partición está montada o no
 	Part_fit   [1]byte  // Tipo de ajuste de la partición (B=Best, F=First, W=Worst)
-	Part_start int32    // Indica en qué byte del disco inicia la partición
-	Part_size  int32    // Contiene el tamaño total de la partición en bytes
-	Part_next  int32    // Byte en el que está el próximo EBR. -1 si no hay siguiente
+	Part_start int64    // Indica en qué byte del disco inicia la partición
+	Part_size  int64    // Contiene el tamaño total de la partición en bytes
+	Part_next  int64    // Byte en el que está el próximo EBR. -1 si no hay siguiente
 	Part_name  [16]byte // Nombre de la partición
 }
 
@@ -43,60 +40,29 @@ func (ebr *EBR) PrintEBR() {
 	fmt.Println("╚════════════════════════════════════╝")
 }
 
-// Serialize escribe el EBR en un archivo binario en una posición específica
-func (ebr *EBR) Serialize(path string, position int) error {
-	file, err := os.OpenFile(path, os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("error abriendo archivo para escribir EBR: %w", err)
-	}
-	defer file.Close()
-
-	// Posicionar en la ubicación del EBR
-	if _, err := file.Seek(int64(position), 0); err != nil {
-		return fmt.Errorf("error posicionando en el archivo: %w", err)
-	}
-
-	// Escribir la estructura EBR
-	var buffer bytes.Buffer
-	if err := binary.Write(&buffer, binary.LittleEndian, ebr); err != nil {
-		return fmt.Errorf("error serializando EBR: %w", err)
-	}
-
-	if _, err := file.Write(buffer.Bytes()); err != nil {
+// Serialize escribe el EBR en el disco en una posición específica
+func (ebr *EBR) Serialize(dev Device, position int) error {
+	if err := writeStruct(dev, int64(position), ebr); err != nil {
 		return fmt.Errorf("error escribiendo EBR: %w", err)
 	}
-
-	return file.Sync()
+	return nil
 }
 
-// Deserialize lee el EBR desde un archivo binario en una posición específica
-func (ebr *EBR) Deserialize(path string, position int) error {
-	file, err := os.Open(path)
-	if err != nil {
-		return fmt.Errorf("error abriendo archivo para leer EBR: %w", err)
-	}
-	defer file.Close()
-
-	// Posicionar en la ubicación del EBR
-	if _, err := file.Seek(int64(position), 0); err != nil {
-		return fmt.Errorf("error posicionando en el archivo: %w", err)
-	}
-
-	// Leer la estructura EBR
-	if err := binary.Read(file, binary.LittleEndian, ebr); err != nil {
+// Deserialize lee el EBR del disco en una posición específica
+func (ebr *EBR) Deserialize(dev Device, position int) error {
+	if err := readStruct(dev, int64(position), ebr); err != nil {
 		return fmt.Errorf("error deserializando EBR: %w", err)
 	}
-
 	return nil
 }
 
 // CreateEBR crea un nuevo EBR con los parámetros dados
 func (ebr *EBR) CreateEBR(start int, size int, fit string, name string, next int) {
-	ebr.Part_mount = [1]byte{'1'}
+	ebr.Part_mount = [1]byte{'0'}
 	ebr.Part_fit = [1]byte{fit[0]}
-	ebr.Part_start = int32(start)
-	ebr.Part_size = int32(size)
-	ebr.Part_next = int32(next)
+	ebr.Part_start = int64(start)
+	ebr.Part_size = int64(size)
+	ebr.Part_next = int64(next)
 	copy(ebr.Part_name[:], name)
 }
 
@@ -105,6 +71,21 @@ func (ebr *EBR) IsEmpty() bool {
 	return ebr.Part_start == -1 || ebr.Part_size == 0
 }
 
+// ToPartition representa la lógica como una Partition con el ID con el que se montó,
+// para que los comandos sobre particiones montadas no distingan primarias de lógicas
+func (ebr *EBR) ToPartition(id string) *Partition {
+	partition := &Partition{
+		Part_status: ebr.Part_mount,
+		Part_type:   [1]byte{'L'},
+		Part_fit:    ebr.Part_fit,
+		Part_start:  ebr.Part_start,
+		Part_size:   ebr.Part_size,
+		Part_name:   ebr.Part_name,
+	}
+	copy(partition.Part_id[:], id)
+	return partition
+}
+
 // Clear limpia el EBR (lo marca como vacío)
 func (ebr *EBR) Clear() {
 	ebr.Part_mount = [1]byte{'0'}
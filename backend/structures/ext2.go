@@ -7,29 +7,29 @@ import (
 )
 
 // Crear users.txt en nuestro sistema de archivos
-func (sb *SuperBlock) CreateUsersFileExt2(path string) error {
+func (sb *SuperBlock) CreateUsersFileExt2(dev Device) error {
 	// ----------- Creamos / -----------
 	// Creamos el inodo raíz
 	rootInode := &Inode{
 		I_uid:   1,
 		I_gid:   1,
 		I_size:  0,
-		I_atime: float32(time.Now().Unix()),
-		I_ctime: float32(time.Now().Unix()),
-		I_mtime: float32(time.Now().Unix()),
+		I_atime: time.Now().UnixNano(),
+		I_ctime: time.Now().UnixNano(),
+		I_mtime: time.Now().UnixNano(),
 		I_block: [15]int32{sb.S_blocks_count, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
 		I_type:  [1]byte{'0'},
 		I_perm:  [3]byte{'7', '7', '7'},
 	}
 
 	// Serializar el inodo raíz
-	err := rootInode.Serialize(path, int64(sb.S_first_ino))
+	err := rootInode.Serialize(dev, int64(sb.S_first_ino))
 	if err != nil {
 		return err
 	}
 
 	// Actualizar el bitmap de inodos
-	err = sb.UpdateBitmapInode(path)
+	err = sb.UpdateBitmapInode(dev)
 	if err != nil {
 		return err
 	}
@@ -37,7 +37,7 @@ func (sb *SuperBlock) CreateUsersFileExt2(path string) error {
 	// Actualizar el superbloque
 	sb.S_inodes_count++
 	sb.S_free_inodes_count--
-	sb.S_first_ino += sb.S_inode_size
+	sb.S_first_ino += int64(sb.S_inode_size)
 
 	// Creamos el bloque del Inodo Raíz
 	rootBlock := &FolderBlock{
@@ -50,13 +50,13 @@ func (sb *SuperBlock) CreateUsersFileExt2(path string) error {
 	}
 
 	// Actualizar el bitmap de bloques
-	err = sb.UpdateBitmapBlock(path)
+	err = sb.UpdateBitmapBlock(dev)
 	if err != nil {
 		return err
 	}
 
 	// Serializar el bloque de carpeta raíz
-	err = rootBlock.Serialize(path, int64(sb.S_first_blo))
+	err = rootBlock.Serialize(dev, int64(sb.S_first_blo))
 	if err != nil {
 		return err
 	}
@@ -64,28 +64,28 @@ func (sb *SuperBlock) CreateUsersFileExt2(path string) error {
 	// Actualizar el superbloque
 	sb.S_blocks_count++
 	sb.S_free_blocks_count--
-	sb.S_first_blo += sb.S_block_size
+	sb.S_first_blo += int64(sb.S_block_size)
 
 	// ----------- Creamos /users.txt -----------
 	usersText := "1,G,root\n1,U,root,root,123\n"
 
 	// Deserializar el inodo raíz
-	err = rootInode.Deserialize(path, int64(sb.S_inode_start+0)) // 0 porque es el inodo raíz
+	err = rootInode.Deserialize(dev, sb.InodeOffset(0)) // 0 porque es el inodo raíz
 	if err != nil {
 		return err
 	}
 
 	// Actualizamos el inodo raíz
-	rootInode.I_atime = float32(time.Now().Unix())
+	rootInode.I_atime = time.Now().UnixNano()
 
 	// Serializar el inodo raíz
-	err = rootInode.Serialize(path, int64(sb.S_inode_start+0)) // 0 porque es el inodo raíz
+	err = rootInode.Serialize(dev, sb.InodeOffset(0)) // 0 porque es el inodo raíz
 	if err != nil {
 		return err
 	}
 
 	// Deserializar el bloque de carpeta raíz
-	err = rootBlock.Deserialize(path, int64(sb.S_block_start+0)) // 0 porque es el bloque de carpeta raíz
+	err = rootBlock.Deserialize(dev, sb.BlockOffset(0)) // 0 porque es el bloque de carpeta raíz
 	if err != nil {
 		return err
 	}
@@ -94,7 +94,7 @@ func (sb *SuperBlock) CreateUsersFileExt2(path string) error {
 	rootBlock.B_content[2] = FolderContent{B_name: [12]byte{'u', 's', 'e', 'r', 's', '.', 't', 'x', 't'}, B_inodo: sb.S_inodes_count}
 
 	// Serializar el bloque de carpeta raíz
-	err = rootBlock.Serialize(path, int64(sb.S_block_start+0)) // 0 porque es el bloque de carpeta raíz
+	err = rootBlock.Serialize(dev, sb.BlockOffset(0)) // 0 porque es el bloque de carpeta raíz
 	if err != nil {
 		return err
 	}
@@ -104,22 +104,22 @@ func (sb *SuperBlock) CreateUsersFileExt2(path string) error {
 		I_uid:   1,
 		I_gid:   1,
 		I_size:  int32(len(usersText)),
-		I_atime: float32(time.Now().Unix()),
-		I_ctime: float32(time.Now().Unix()),
-		I_mtime: float32(time.Now().Unix()),
+		I_atime: time.Now().UnixNano(),
+		I_ctime: time.Now().UnixNano(),
+		I_mtime: time.Now().UnixNano(),
 		I_block: [15]int32{sb.S_blocks_count, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
 		I_type:  [1]byte{'1'},
 		I_perm:  [3]byte{'7', '7', '7'},
 	}
 
 	// Actualizar el bitmap de inodos
-	err = sb.UpdateBitmapInode(path)
+	err = sb.UpdateBitmapInode(dev)
 	if err != nil {
 		return err
 	}
 
 	// Serializar el inodo users.txt
-	err = usersInode.Serialize(path, int64(sb.S_first_ino))
+	err = usersInode.Serialize(dev, int64(sb.S_first_ino))
 	if err != nil {
 		return err
 	}
@@ -127,7 +127,7 @@ func (sb *SuperBlock) CreateUsersFileExt2(path string) error {
 	// Actualizamos el superbloque
 	sb.S_inodes_count++
 	sb.S_free_inodes_count--
-	sb.S_first_ino += sb.S_inode_size
+	sb.S_first_ino += int64(sb.S_inode_size)
 
 	// Creamos el bloque de users.txt
 	usersBlock := &FileBlock{
@@ -137,13 +137,13 @@ func (sb *SuperBlock) CreateUsersFileExt2(path string) error {
 	copy(usersBlock.B_content[:], usersText)
 
 	// Serializar el bloque de users.txt
-	err = usersBlock.Serialize(path, int64(sb.S_first_blo))
+	err = usersBlock.Serialize(dev, int64(sb.S_first_blo))
 	if err != nil {
 		return err
 	}
 
 	// Actualizar el bitmap de bloques
-	err = sb.UpdateBitmapBlock(path)
+	err = sb.UpdateBitmapBlock(dev)
 	if err != nil {
 		return err
 	}
@@ -151,17 +151,17 @@ func (sb *SuperBlock) CreateUsersFileExt2(path string) error {
 	// Actualizamos el superbloque
 	sb.S_blocks_count++
 	sb.S_free_blocks_count--
-	sb.S_first_blo += sb.S_block_size
+	sb.S_first_blo += int64(sb.S_block_size)
 
 	return nil
 }
 
 // createFolderInInode crea una carpeta en un inodo específico
-func (sb *SuperBlock) createFolderInInodeExt2(path string, inodeIndex int32, parentsDir []string, destDir string) error {
+func (sb *SuperBlock) createFolderInInodeExt2(dev Device, inodeIndex int32, parentsDir []string, destDir string) error {
 	// Crear un nuevo inodo
 	inode := &Inode{}
 	// Deserializar el inodo
-	err := inode.Deserialize(path, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+	err := inode.Deserialize(dev, sb.InodeOffset(int64(inodeIndex)))
 	if err != nil {
 		return err
 	}
@@ -181,7 +181,7 @@ func (sb *SuperBlock) createFolderInInodeExt2(path string, inodeIndex int32, par
 		block := &FolderBlock{}
 
 		// Deserializar el bloque
-		err := block.Deserialize(path, int64(sb.S_block_start+(blockIndex*sb.S_block_size))) // 64 porque es el tamaño de un bloque
+		err := block.Deserialize(dev, sb.BlockOffset(int64(blockIndex)))
 		if err != nil {
 			return err
 		}
@@ -214,7 +214,7 @@ func (sb *SuperBlock) createFolderInInodeExt2(path string, inodeIndex int32, par
 				if strings.EqualFold(contentName, parentDirName) {
 					//fmt.Println("---------LA ENCONTRÉ-------")
 					// Si son las mismas, entonces entramos al inodo que apunta el bloque
-					err := sb.createFolderInInodeExt2(path, content.B_inodo, utils.RemoveElement(parentsDir, 0), destDir)
+					err := sb.createFolderInInodeExt2(dev, content.B_inodo, utils.RemoveElement(parentsDir, 0), destDir)
 					if err != nil {
 						return err
 					}
@@ -236,7 +236,7 @@ func (sb *SuperBlock) createFolderInInodeExt2(path string, inodeIndex int32, par
 				block.B_content[indexContent] = content
 
 				// Serializar el bloque
-				err = block.Serialize(path, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
+				err = block.Serialize(dev, sb.BlockOffset(int64(blockIndex)))
 				if err != nil {
 					return err
 				}
@@ -246,22 +246,22 @@ func (sb *SuperBlock) createFolderInInodeExt2(path string, inodeIndex int32, par
 					I_uid:   1,
 					I_gid:   1,
 					I_size:  0,
-					I_atime: float32(time.Now().Unix()),
-					I_ctime: float32(time.Now().Unix()),
-					I_mtime: float32(time.Now().Unix()),
+					I_atime: time.Now().UnixNano(),
+					I_ctime: time.Now().UnixNano(),
+					I_mtime: time.Now().UnixNano(),
 					I_block: [15]int32{sb.S_blocks_count, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
 					I_type:  [1]byte{'0'},
 					I_perm:  [3]byte{'6', '6', '4'},
 				}
 
 				// Serializar el inodo de la carpeta
-				err = folderInode.Serialize(path, int64(sb.S_first_ino))
+				err = folderInode.Serialize(dev, int64(sb.S_first_ino))
 				if err != nil {
 					return err
 				}
 
 				// Actualizar el bitmap de inodos
-				err = sb.UpdateBitmapInode(path)
+				err = sb.UpdateBitmapInode(dev)
 				if err != nil {
 					return err
 				}
@@ -269,7 +269,7 @@ func (sb *SuperBlock) createFolderInInodeExt2(path string, inodeIndex int32, par
 				// Actualizar el superbloque
 				sb.S_inodes_count++
 				sb.S_free_inodes_count--
-				sb.S_first_ino += sb.S_inode_size
+				sb.S_first_ino += int64(sb.S_inode_size)
 
 				// Crear el bloque de la carpeta
 				folderBlock := &FolderBlock{
@@ -282,13 +282,13 @@ func (sb *SuperBlock) createFolderInInodeExt2(path string, inodeIndex int32, par
 				}
 
 				// Serializar el bloque de la carpeta
-				err = folderBlock.Serialize(path, int64(sb.S_first_blo))
+				err = folderBlock.Serialize(dev, int64(sb.S_first_blo))
 				if err != nil {
 					return err
 				}
 
 				// Actualizar el bitmap de bloques
-				err = sb.UpdateBitmapBlock(path)
+				err = sb.UpdateBitmapBlock(dev)
 				if err != nil {
 					return err
 				}
@@ -296,7 +296,7 @@ func (sb *SuperBlock) createFolderInInodeExt2(path string, inodeIndex int32, par
 				// Actualizar el superbloque
 				sb.S_blocks_count++
 				sb.S_free_blocks_count--
-				sb.S_first_blo += sb.S_block_size
+				sb.S_first_blo += int64(sb.S_block_size)
 
 				return nil
 			}
@@ -306,24 +306,24 @@ func (sb *SuperBlock) createFolderInInodeExt2(path string, inodeIndex int32, par
 	return nil
 }
 
-func (sb *SuperBlock) CreateFileExt2(path string, filename string, content string) error {
+func (sb *SuperBlock) CreateFileExt2(dev Device, filename string, content string) error {
 	// Deserializar el inodo raíz
 	rootInode := &Inode{}
-	err := rootInode.Deserialize(path, int64(sb.S_inode_start+0))
+	err := rootInode.Deserialize(dev, sb.InodeOffset(0))
 	if err != nil {
 		return err
 	}
 
 	// Actualizar tiempo de acceso del inodo raíz
-	rootInode.I_atime = float32(time.Now().Unix())
-	err = rootInode.Serialize(path, int64(sb.S_inode_start+0))
+	rootInode.I_atime = time.Now().UnixNano()
+	err = rootInode.Serialize(dev, sb.InodeOffset(0))
 	if err != nil {
 		return err
 	}
 
 	// Deserializar el bloque raíz
 	rootBlock := &FolderBlock{}
-	err = rootBlock.Deserialize(path, int64(sb.S_block_start+0))
+	err = rootBlock.Deserialize(dev, sb.BlockOffset(0))
 	if err != nil {
 		return err
 	}
@@ -333,22 +333,22 @@ func (sb *SuperBlock) CreateFileExt2(path string, filename string, content strin
 		I_uid:   1,
 		I_gid:   1,
 		I_size:  int32(len(content)),
-		I_atime: float32(time.Now().Unix()),
-		I_ctime: float32(time.Now().Unix()),
-		I_mtime: float32(time.Now().Unix()),
+		I_atime: time.Now().UnixNano(),
+		I_ctime: time.Now().UnixNano(),
+		I_mtime: time.Now().UnixNano(),
 		I_block: [15]int32{sb.S_blocks_count, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
 		I_type:  [1]byte{'1'}, // Tipo archivo
 		I_perm:  [3]byte{'7', '7', '7'},
 	}
 
 	// Actualizar bitmap de inodos
-	err = sb.UpdateBitmapInode(path)
+	err = sb.UpdateBitmapInode(dev)
 	if err != nil {
 		return err
 	}
 
 	// Serializar el inodo del archivo
-	err = fileInode.Serialize(path, int64(sb.S_first_ino))
+	err = fileInode.Serialize(dev, int64(sb.S_first_ino))
 	if err != nil {
 		return err
 	}
@@ -356,7 +356,7 @@ func (sb *SuperBlock) CreateFileExt2(path string, filename string, content strin
 	// Actualizar contadores de inodos
 	sb.S_inodes_count++
 	sb.S_free_inodes_count--
-	sb.S_first_ino += sb.S_inode_size
+	sb.S_first_ino += int64(sb.S_inode_size)
 
 	// Crear bloque de archivo
 	fileBlock := &FileBlock{
@@ -365,13 +365,13 @@ func (sb *SuperBlock) CreateFileExt2(path string, filename string, content strin
 	copy(fileBlock.B_content[:], content)
 
 	// Serializar bloque de archivo
-	err = fileBlock.Serialize(path, int64(sb.S_first_blo))
+	err = fileBlock.Serialize(dev, int64(sb.S_first_blo))
 	if err != nil {
 		return err
 	}
 
 	// Actualizar bitmap de bloques
-	err = sb.UpdateBitmapBlock(path)
+	err = sb.UpdateBitmapBlock(dev)
 	if err != nil {
 		return err
 	}
@@ -379,7 +379,7 @@ func (sb *SuperBlock) CreateFileExt2(path string, filename string, content strin
 	// Actualizar contadores de bloques
 	sb.S_blocks_count++
 	sb.S_free_blocks_count--
-	sb.S_first_blo += sb.S_block_size
+	sb.S_first_blo += int64(sb.S_block_size)
 
 	// Actualizar bloque raíz para añadir referencia al nuevo archivo
 	for i := 2; i < len(rootBlock.B_content); i++ {
@@ -391,7 +391,7 @@ func (sb *SuperBlock) CreateFileExt2(path string, filename string, content strin
 	}
 
 	// Serializar bloque raíz actualizado
-	err = rootBlock.Serialize(path, int64(sb.S_block_start+0))
+	err = rootBlock.Serialize(dev, sb.BlockOffset(0))
 	if err != nil {
 		return err
 	}
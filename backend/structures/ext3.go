@@ -7,7 +7,7 @@ import (
 )
 
 // Crear users.txt en nuestro sistema de archivos
-func (sb *SuperBlock) CreateUsersFileExt3(path string, journauling_start int64) error {
+func (sb *SuperBlock) CreateUsersFileExt3(dev Device, journauling_start int64) error {
 	// ----------- Creamos / -----------
 	// Crear Journal
 
@@ -16,22 +16,22 @@ func (sb *SuperBlock) CreateUsersFileExt3(path string, journauling_start int64)
 		I_uid:   1,
 		I_gid:   1,
 		I_size:  0,
-		I_atime: float32(time.Now().Unix()),
-		I_ctime: float32(time.Now().Unix()),
-		I_mtime: float32(time.Now().Unix()),
+		I_atime: time.Now().UnixNano(),
+		I_ctime: time.Now().UnixNano(),
+		I_mtime: time.Now().UnixNano(),
 		I_block: [15]int32{sb.S_blocks_count, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
 		I_type:  [1]byte{'0'},
 		I_perm:  [3]byte{'7', '7', '7'},
 	}
 
 	// Serializar el inodo raíz
-	err := rootInode.Serialize(path, int64(sb.S_first_ino))
+	err := rootInode.Serialize(dev, int64(sb.S_first_ino))
 	if err != nil {
 		return err
 	}
 
 	// Actualizar el bitmap de inodos
-	err = sb.UpdateBitmapInode(path)
+	err = sb.UpdateBitmapInode(dev)
 	if err != nil {
 		return err
 	}
@@ -39,7 +39,7 @@ func (sb *SuperBlock) CreateUsersFileExt3(path string, journauling_start int64)
 	// Actualizar el superbloque
 	sb.S_inodes_count++
 	sb.S_free_inodes_count--
-	sb.S_first_ino += sb.S_inode_size
+	sb.S_first_ino += int64(sb.S_inode_size)
 
 	// Creamos el bloque del Inodo Raíz
 	rootBlock := &FolderBlock{
@@ -52,13 +52,13 @@ func (sb *SuperBlock) CreateUsersFileExt3(path string, journauling_start int64)
 	}
 
 	// Actualizar el bitmap de bloques
-	err = sb.UpdateBitmapBlock(path)
+	err = sb.UpdateBitmapBlock(dev)
 	if err != nil {
 		return err
 	}
 
 	// Serializar el bloque de carpeta raíz
-	err = rootBlock.Serialize(path, int64(sb.S_first_blo))
+	err = rootBlock.Serialize(dev, int64(sb.S_first_blo))
 	if err != nil {
 		return err
 	}
@@ -66,7 +66,7 @@ func (sb *SuperBlock) CreateUsersFileExt3(path string, journauling_start int64)
 	// Actualizar el superbloque
 	sb.S_blocks_count++
 	sb.S_free_blocks_count--
-	sb.S_first_blo += sb.S_block_size
+	sb.S_first_blo += int64(sb.S_block_size)
 
 	journal := &Journal{
 		J_count: sb.S_inodes_count,
@@ -74,12 +74,12 @@ func (sb *SuperBlock) CreateUsersFileExt3(path string, journauling_start int64)
 			I_operation: [10]byte{'m', 'k', 'd', 'i', 'r'},
 			I_path:      [32]byte{'/'},
 			I_content:   [64]byte{},
-			I_date:      float32(time.Now().Unix()),
+			I_date:      time.Now().UnixNano(),
 		},
 	}
 
 	// Serializar el journal
-	err = journal.Serialize(path, journauling_start)
+	err = journal.Serialize(dev, journauling_start)
 	if err != nil {
 		return err
 	}
@@ -88,22 +88,22 @@ func (sb *SuperBlock) CreateUsersFileExt3(path string, journauling_start int64)
 	usersText := "1,G,root\n1,U,root,root,123\n"
 
 	// Deserializar el inodo raíz
-	err = rootInode.Deserialize(path, int64(sb.S_inode_start+0)) // 0 porque es el inodo raíz
+	err = rootInode.Deserialize(dev, sb.InodeOffset(0)) // 0 porque es el inodo raíz
 	if err != nil {
 		return err
 	}
 
 	// Actualizamos el inodo raíz
-	rootInode.I_atime = float32(time.Now().Unix())
+	rootInode.I_atime = time.Now().UnixNano()
 
 	// Serializar el inodo raíz
-	err = rootInode.Serialize(path, int64(sb.S_inode_start+0)) // 0 porque es el inodo raíz
+	err = rootInode.Serialize(dev, sb.InodeOffset(0)) // 0 porque es el inodo raíz
 	if err != nil {
 		return err
 	}
 
 	// Deserializar el bloque de carpeta raíz
-	err = rootBlock.Deserialize(path, int64(sb.S_block_start+0)) // 0 porque es el bloque de carpeta raíz
+	err = rootBlock.Deserialize(dev, sb.BlockOffset(0)) // 0 porque es el bloque de carpeta raíz
 	if err != nil {
 		return err
 	}
@@ -112,7 +112,7 @@ func (sb *SuperBlock) CreateUsersFileExt3(path string, journauling_start int64)
 	rootBlock.B_content[2] = FolderContent{B_name: [12]byte{'u', 's', 'e', 'r', 's', '.', 't', 'x', 't'}, B_inodo: sb.S_inodes_count}
 
 	// Serializar el bloque de carpeta raíz
-	err = rootBlock.Serialize(path, int64(sb.S_block_start+0)) // 0 porque es el bloque de carpeta raíz
+	err = rootBlock.Serialize(dev, sb.BlockOffset(0)) // 0 porque es el bloque de carpeta raíz
 	if err != nil {
 		return err
 	}
@@ -122,22 +122,22 @@ func (sb *SuperBlock) CreateUsersFileExt3(path string, journauling_start int64)
 		I_uid:   1,
 		I_gid:   1,
 		I_size:  int32(len(usersText)),
-		I_atime: float32(time.Now().Unix()),
-		I_ctime: float32(time.Now().Unix()),
-		I_mtime: float32(time.Now().Unix()),
+		I_atime: time.Now().UnixNano(),
+		I_ctime: time.Now().UnixNano(),
+		I_mtime: time.Now().UnixNano(),
 		I_block: [15]int32{sb.S_blocks_count, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
 		I_type:  [1]byte{'1'},
 		I_perm:  [3]byte{'7', '7', '7'},
 	}
 
 	// Actualizar el bitmap de inodos
-	err = sb.UpdateBitmapInode(path)
+	err = sb.UpdateBitmapInode(dev)
 	if err != nil {
 		return err
 	}
 
 	// Serializar el inodo users.txt
-	err = usersInode.Serialize(path, int64(sb.S_first_ino))
+	err = usersInode.Serialize(dev, int64(sb.S_first_ino))
 	if err != nil {
 		return err
 	}
@@ -145,7 +145,7 @@ func (sb *SuperBlock) CreateUsersFileExt3(path string, journauling_start int64)
 	// Actualizamos el superbloque
 	sb.S_inodes_count++
 	sb.S_free_inodes_count--
-	sb.S_first_ino += sb.S_inode_size
+	sb.S_first_ino += int64(sb.S_inode_size)
 
 	// Crear Journal
 	journalFile := &Journal{
@@ -154,14 +154,14 @@ func (sb *SuperBlock) CreateUsersFileExt3(path string, journauling_start int64)
 			I_operation: [10]byte{'m', 'k', 'f', 'i', 'l', 'e'},
 			I_path:      [32]byte{'/', 'u', 's', 'e', 'r', 's', '.', 't', 'x', 't'},
 			I_content:   [64]byte{},
-			I_date:      float32(time.Now().Unix()),
+			I_date:      time.Now().UnixNano(),
 		},
 	}
 	// Copiamos el texto de usuarios en el journal
 	copy(journalFile.J_content.I_content[:], usersText)
 
 	// Serializar el journal
-	err = journalFile.Serialize(path, journauling_start)
+	err = journalFile.Serialize(dev, journauling_start)
 	if err != nil {
 		return err
 	}
@@ -174,13 +174,13 @@ func (sb *SuperBlock) CreateUsersFileExt3(path string, journauling_start int64)
 	copy(usersBlock.B_content[:], usersText)
 
 	// Serializar el bloque de users.txt
-	err = usersBlock.Serialize(path, int64(sb.S_first_blo))
+	err = usersBlock.Serialize(dev, int64(sb.S_first_blo))
 	if err != nil {
 		return err
 	}
 
 	// Actualizar el bitmap de bloques
-	err = sb.UpdateBitmapBlock(path)
+	err = sb.UpdateBitmapBlock(dev)
 	if err != nil {
 		return err
 	}
@@ -188,17 +188,17 @@ func (sb *SuperBlock) CreateUsersFileExt3(path string, journauling_start int64)
 	// Actualizamos el superbloque
 	sb.S_blocks_count++
 	sb.S_free_blocks_count--
-	sb.S_first_blo += sb.S_block_size
+	sb.S_first_blo += int64(sb.S_block_size)
 
 	return nil
 }
 
 // createFolderInInode crea una carpeta en un inodo específico
-func (sb *SuperBlock) createFolderInInodeExt3(path string, inodeIndex int32, parentsDir []string, destDir string) error {
+func (sb *SuperBlock) createFolderInInodeExt3(dev Device, inodeIndex int32, parentsDir []string, destDir string) error {
 	// Crear un nuevo inodo
 	inode := &Inode{}
 	// Deserializar el inodo
-	err := inode.Deserialize(path, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+	err := inode.Deserialize(dev, sb.InodeOffset(int64(inodeIndex)))
 	if err != nil {
 		return err
 	}
@@ -218,7 +218,7 @@ func (sb *SuperBlock) createFolderInInodeExt3(path string, inodeIndex int32, par
 		block := &FolderBlock{}
 
 		// Deserializar el bloque
-		err := block.Deserialize(path, int64(sb.S_block_start+(blockIndex*sb.S_block_size))) // 64 porque es el tamaño de un bloque
+		err := block.Deserialize(dev, sb.BlockOffset(int64(blockIndex)))
 		if err != nil {
 			return err
 		}
@@ -251,7 +251,7 @@ func (sb *SuperBlock) createFolderInInodeExt3(path string, inodeIndex int32, par
 				if strings.EqualFold(contentName, parentDirName) {
 					//fmt.Println("---------LA ENCONTRÉ-------")
 					// Si son las mismas, entonces entramos al inodo que apunta el bloque
-					err := sb.createFolderInInodeExt3(path, content.B_inodo, utils.RemoveElement(parentsDir, 0), destDir)
+					err := sb.createFolderInInodeExt3(dev, content.B_inodo, utils.RemoveElement(parentsDir, 0), destDir)
 					if err != nil {
 						return err
 					}
@@ -273,7 +273,7 @@ func (sb *SuperBlock) createFolderInInodeExt3(path string, inodeIndex int32, par
 				block.B_content[indexContent] = content
 
 				// Serializar el bloque
-				err = block.Serialize(path, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
+				err = block.Serialize(dev, sb.BlockOffset(int64(blockIndex)))
 				if err != nil {
 					return err
 				}
@@ -285,22 +285,22 @@ func (sb *SuperBlock) createFolderInInodeExt3(path string, inodeIndex int32, par
 					I_uid:   1,
 					I_gid:   1,
 					I_size:  0,
-					I_atime: float32(time.Now().Unix()),
-					I_ctime: float32(time.Now().Unix()),
-					I_mtime: float32(time.Now().Unix()),
+					I_atime: time.Now().UnixNano(),
+					I_ctime: time.Now().UnixNano(),
+					I_mtime: time.Now().UnixNano(),
 					I_block: [15]int32{sb.S_blocks_count, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
 					I_type:  [1]byte{'0'},
 					I_perm:  [3]byte{'6', '6', '4'},
 				}
 
 				// Serializar el inodo de la carpeta
-				err = folderInode.Serialize(path, int64(sb.S_first_ino))
+				err = folderInode.Serialize(dev, int64(sb.S_first_ino))
 				if err != nil {
 					return err
 				}
 
 				// Actualizar el bitmap de inodos
-				err = sb.UpdateBitmapInode(path)
+				err = sb.UpdateBitmapInode(dev)
 				if err != nil {
 					return err
 				}
@@ -308,7 +308,7 @@ func (sb *SuperBlock) createFolderInInodeExt3(path string, inodeIndex int32, par
 				// Actualizar el superbloque
 				sb.S_inodes_count++
 				sb.S_free_inodes_count--
-				sb.S_first_ino += sb.S_inode_size
+				sb.S_first_ino += int64(sb.S_inode_size)
 
 				// TODO: Ponen su Journal
 
@@ -323,13 +323,13 @@ func (sb *SuperBlock) createFolderInInodeExt3(path string, inodeIndex int32, par
 				}
 
 				// Serializar el bloque de la carpeta
-				err = folderBlock.Serialize(path, int64(sb.S_first_blo))
+				err = folderBlock.Serialize(dev, int64(sb.S_first_blo))
 				if err != nil {
 					return err
 				}
 
 				// Actualizar el bitmap de bloques
-				err = sb.UpdateBitmapBlock(path)
+				err = sb.UpdateBitmapBlock(dev)
 				if err != nil {
 					return err
 				}
@@ -337,7 +337,7 @@ func (sb *SuperBlock) createFolderInInodeExt3(path string, inodeIndex int32, par
 				// Actualizar el superbloque
 				sb.S_blocks_count++
 				sb.S_free_blocks_count--
-				sb.S_first_blo += sb.S_block_size
+				sb.S_first_blo += int64(sb.S_block_size)
 
 				return nil
 			}
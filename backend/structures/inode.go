@@ -1,10 +1,7 @@
 package structures
 
 import (
-	"bytes"
-	"encoding/binary"
 	"fmt"
-	"os"
 	"time"
 )
 
@@ -12,80 +9,30 @@ type Inode struct {
 	I_uid   int32
 	I_gid   int32
 	I_size  int32
-	I_atime float32
-	I_ctime float32
-	I_mtime float32
+	I_atime int64 // Nanosegundos Unix
+	I_ctime int64
+	I_mtime int64
 	I_block [15]int32
 	I_type  [1]byte
 	I_perm  [3]byte
-	// Total: 88 bytes
+	// Total: 100 bytes (InodeSize); cada entrada de la tabla ocupa S_inode_size
 }
 
-// Serialize escribe la estructura Inode en un archivo binario en la posición especificada
-func (inode *Inode) Serialize(path string, offset int64) error {
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	// Mover el puntero del archivo a la posición especificada
-	_, err = file.Seek(offset, 0)
-	if err != nil {
-		return err
-	}
-
-	// Serializar la estructura Inode directamente en el archivo
-	err = binary.Write(file, binary.LittleEndian, inode)
-	if err != nil {
-		return err
-	}
-
-	return nil
+// Serialize escribe la estructura Inode en el disco en la posición especificada
+func (inode *Inode) Serialize(dev Device, offset int64) error {
+	return writeStruct(dev, offset, inode)
 }
 
-// Deserialize lee la estructura Inode desde un archivo binario en la posición especificada
-func (inode *Inode) Deserialize(path string, offset int64) error {
-	file, err := os.Open(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	// Mover el puntero del archivo a la posición especificada
-	_, err = file.Seek(offset, 0)
-	if err != nil {
-		return err
-	}
-
-	// Obtener el tamaño de la estructura Inode
-	inodeSize := binary.Size(inode)
-	if inodeSize <= 0 {
-		return fmt.Errorf("invalid Inode size: %d", inodeSize)
-	}
-
-	// Leer solo la cantidad de bytes que corresponden al tamaño de la estructura Inode
-	buffer := make([]byte, inodeSize)
-	_, err = file.Read(buffer)
-	if err != nil {
-		return err
-	}
-
-	// Deserializar los bytes leídos en la estructura Inode
-	reader := bytes.NewReader(buffer)
-	err = binary.Read(reader, binary.LittleEndian, inode)
-	if err != nil {
-		return err
-	}
-
-	return nil
+// Deserialize lee la estructura Inode del disco en la posición especificada
+func (inode *Inode) Deserialize(dev Device, offset int64) error {
+	return readStruct(dev, offset, inode)
 }
 
 // Print imprime los atributos del inodo
 func (inode *Inode) Print() {
-	atime := time.Unix(int64(inode.I_atime), 0)
-	ctime := time.Unix(int64(inode.I_ctime), 0)
-	mtime := time.Unix(int64(inode.I_mtime), 0)
+	atime := time.Unix(0, inode.I_atime)
+	ctime := time.Unix(0, inode.I_ctime)
+	mtime := time.Unix(0, inode.I_mtime)
 
 	fmt.Printf("I_uid: %d\n", inode.I_uid)
 	fmt.Printf("I_gid: %d\n", inode.I_gid)
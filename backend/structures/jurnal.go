@@ -1,78 +1,40 @@
 package structures
 
 import (
-	"encoding/binary"
 	"fmt"
-	"os"
 	"time"
 )
 
 type Journal struct {
 	J_count   int32       // 4 bytes
-	J_content Information // 110 bytes
-	// Total: 114 bytes
+	J_content Information // 114 bytes
+	// Total: 118 bytes (JournalEntrySize)
 }
 
 type Information struct {
 	I_operation [10]byte // 10 bytes
 	I_path      [32]byte // 32 bytes
 	I_content   [64]byte // 64 bytes
-	I_date      float32  // 4 bytes
-	// Total: 110 bytes
+	I_date      int64    // 8 bytes, nanosegundos Unix
+	// Total: 114 bytes
 }
 
-// SerializeJournal escribe la estructura Journal en un archivo binario
-func (journal *Journal) Serialize(path string, journauling_start int64) error {
-	// Calcular la posición en el archivo
-	offset := journauling_start + (int64(binary.Size(Journal{})) * int64(journal.J_count))
-
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	// Mover el puntero del archivo a la posición especificada
-	_, err = file.Seek(offset, 0)
-	if err != nil {
-		return err
-	}
-
-	// Serializar la estructura Journal directamente en el archivo
-	err = binary.Write(file, binary.LittleEndian, journal)
-	if err != nil {
-		return err
-	}
-
-	return nil
+// SerializeJournal escribe la entrada del journal en su posición según J_count
+func (journal *Journal) Serialize(dev Device, journauling_start int64) error {
+	// Calcular la posición en el disco
+	offset := journauling_start + JournalEntrySize*int64(journal.J_count)
+	return writeStruct(dev, offset, journal)
 }
 
-// DeserializeJournal lee la estructura Journal desde un archivo binario
-func (journal *Journal) Deserialize(path string, offset int64) error {
-	file, err := os.Open(path)
-	if err != nil {
-		return err
-	}
-
-	// Mover el puntero del archivo a la posición especificada
-	_, err = file.Seek(offset, 0)
-	if err != nil {
-		return err
-	}
-
-	// Deserializar la estructura Journal directamente desde el archivo
-	err = binary.Read(file, binary.LittleEndian, journal)
-	if err != nil {
-		return err
-	}
-
-	return nil
+// DeserializeJournal lee una entrada del journal desde el disco
+func (journal *Journal) Deserialize(dev Device, offset int64) error {
+	return readStruct(dev, offset, journal)
 }
 
 // PrintJournal imprime en consola la estructura Journal
 func (journal *Journal) Print() {
 	// Convertir el tiempo de montaje a una fecha
-	date := time.Unix(int64(journal.J_content.I_date), 0)
+	date := time.Unix(0, journal.J_content.I_date)
 
 	fmt.Println("Journal:")
 	fmt.Printf("J_count: %d", journal.J_count)
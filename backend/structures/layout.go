@@ -0,0 +1,191 @@
+package structures
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Tamaños en disco de las estructuras del sistema de archivos. Salen de binary.Size
+// para que ningún offset dependa de un número escrito a mano.
+var (
+	SuperBlockSize   = int64(binary.Size(SuperBlock{}))
+	InodeSize        = int64(binary.Size(Inode{}))
+	BlockSize        = int64(binary.Size(FolderBlock{})) // Igual para FileBlock y PointerBlock
+	JournalEntrySize = int64(binary.Size(Journal{}))
+)
+
+// JournalEntries entradas que mkfs reserva para el journal de EXT3
+const JournalEntries = 50
+
+// Layout distribución de un sistema EXT2/EXT3 dentro de su partición:
+//
+//	superbloque | journal (EXT3) | bitmap de inodos | bitmap de bloques | inodos | bloques
+//
+// Los offsets son absolutos, como los del superbloque.
+type Layout struct {
+	PartStart int64
+	PartSize  int64
+	Ext3      bool
+	Packed    bool
+	Inodes    int64 // Capacidad de la tabla de inodos; los bloques son el triple
+	InodeSize int64 // Bytes que ocupa cada inodo en la tabla
+	BlockSize int64
+
+	JournalStart int64
+	JournalSize  int64
+	BmInodeStart int64
+	BmBlockStart int64
+	InodeStart   int64
+	BlockStart   int64
+	End          int64 // Primer byte después del último bloque
+}
+
+// NewLayout distribución que usa mkfs en una partición de size bytes que empieza en start
+func NewLayout(start, size int64, ext3, packed bool) Layout {
+	l := Layout{PartStart: start, PartSize: size, Ext3: ext3, Packed: packed, InodeSize: InodeSize, BlockSize: BlockSize}
+	if ext3 {
+		l.JournalSize = JournalEntries * JournalEntrySize
+	}
+	l.place(l.fitInodes())
+	return l
+}
+
+// Layout distribución del sistema de archivos ya formateado en la partición. Los tamaños
+// de inodo y de journal salen del superbloque, así que también sirve para particiones
+// formateadas con otros tamaños de entrada.
+func (sb *SuperBlock) Layout(start, size int64) (Layout, error) {
+	l := Layout{
+		PartStart: start,
+		PartSize:  size,
+		Ext3:      sb.S_filesystem_type == 3,
+		Packed:    sb.PackedBitmaps(),
+		InodeSize: int64(sb.S_inode_size),
+		BlockSize: int64(sb.S_block_size),
+	}
+	if l.Ext3 {
+		l.JournalSize = sb.S_bm_inode_start - start - SuperBlockSize
+	}
+
+	if l.InodeSize < InodeSize || l.BlockSize < BlockSize || l.JournalSize < 0 {
+		return Layout{}, fmt.Errorf("el superbloque no tiene la distribución esperada (inodo %d, bloque %d, journal %d)", l.InodeSize, l.BlockSize, l.JournalSize)
+	}
+	l.place(sb.InodeCapacity())
+	if l.Inodes <= 0 || l.BmInodeStart != sb.S_bm_inode_start || l.BmBlockStart != sb.S_bm_block_start ||
+		l.InodeStart != sb.S_inode_start || l.BlockStart != sb.S_block_start {
+		return Layout{}, fmt.Errorf("los offsets del superbloque no corresponden a %d inodos", l.Inodes)
+	}
+	return l, nil
+}
+
+// Resize misma distribución (tamaños de inodo, bloque y journal) con la mayor cantidad
+// de inodos que cabe en size bytes
+func (l Layout) Resize(size int64) Layout {
+	l.PartSize = size
+	l.place(l.fitInodes())
+	return l
+}
+
+// Fits indica si todas las estructuras caben en la partición
+func (l Layout) Fits() bool {
+	return l.End <= l.PartStart+l.PartSize
+}
+
+// Blocks cantidad de bloques de la tabla
+func (l Layout) Blocks() int64 {
+	return 3 * l.Inodes
+}
+
+// InodeOffset posición del inodo index
+func (l Layout) InodeOffset(index int64) int64 {
+	return l.InodeStart + index*l.InodeSize
+}
+
+// BlockOffset posición del bloque index
+func (l Layout) BlockOffset(index int64) int64 {
+	return l.BlockStart + index*l.BlockSize
+}
+
+// Apply copia la distribución al superbloque; los contadores no cambian
+func (l Layout) Apply(sb *SuperBlock) {
+	sb.S_inode_size = int32(l.InodeSize)
+	sb.S_block_size = int32(l.BlockSize)
+	sb.S_bm_inode_start = l.BmInodeStart
+	sb.S_bm_block_start = l.BmBlockStart
+	sb.S_inode_start = l.InodeStart
+	sb.S_block_start = l.BlockStart
+	if l.Packed {
+		sb.S_features |= FeaturePackedBitmaps
+	} else {
+		sb.S_features &^= FeaturePackedBitmaps
+	}
+}
+
+// LayoutRegion zona de la partición [Start, End)
+type LayoutRegion struct {
+	Name  string
+	Start int64
+	End   int64
+}
+
+// Regions zonas de la distribución en orden; la última es el espacio sin usar
+func (l Layout) Regions() []LayoutRegion {
+	regions := []LayoutRegion{{"superbloque", l.PartStart, l.PartStart + SuperBlockSize}}
+	if l.Ext3 {
+		regions = append(regions, LayoutRegion{
+			fmt.Sprintf("journal (%d entradas de %d bytes)", l.JournalSize/JournalEntrySize, JournalEntrySize),
+			l.JournalStart, l.BmInodeStart,
+		})
+	}
+	return append(regions,
+		LayoutRegion{fmt.Sprintf("bitmap de inodos (%d)", l.Inodes), l.BmInodeStart, l.BmBlockStart},
+		LayoutRegion{fmt.Sprintf("bitmap de bloques (%d)", l.Blocks()), l.BmBlockStart, l.InodeStart},
+		LayoutRegion{fmt.Sprintf("inodos (%d de %d bytes)", l.Inodes, l.InodeSize), l.InodeStart, l.BlockStart},
+		LayoutRegion{fmt.Sprintf("bloques (%d de %d bytes)", l.Blocks(), l.BlockSize), l.BlockStart, l.End},
+		LayoutRegion{"sin usar", l.End, max(l.End, l.PartStart+l.PartSize)},
+	)
+}
+
+// place calcula los offsets para una tabla de n inodos
+func (l *Layout) place(n int64) {
+	l.Inodes = n
+	l.JournalStart = l.PartStart + SuperBlockSize
+	l.BmInodeStart = l.JournalStart + l.JournalSize
+	l.BmBlockStart = l.BmInodeStart + BitmapSize(n, l.Packed)
+	l.InodeStart = l.BmBlockStart + BitmapSize(3*n, l.Packed)
+	l.BlockStart = l.InodeStart + n*l.InodeSize
+	l.End = l.BlockStart + 3*n*l.BlockSize
+}
+
+// fitInodes mayor n cuyas estructuras caben en la partición (mínimo 3). Cada inodo
+// ocupa su entrada, tres bloques y 4 bytes de bitmaps (4 bits si son de un bit).
+func (l Layout) fitInodes() int64 {
+	free := l.PartSize - SuperBlockSize - l.JournalSize
+	perInode := l.InodeSize + 3*l.BlockSize
+
+	var n int64
+	if l.Packed {
+		// Los bitmaps se redondean a bytes: se parte de la aproximación y se baja
+		n = free * 8 / (4 + 8*perInode)
+		for n > 3 {
+			probe := l
+			probe.place(n)
+			if probe.Fits() {
+				break
+			}
+			n--
+		}
+	} else {
+		n = free / (4 + perInode)
+	}
+	return max(n, 3)
+}
+
+// InodeOffset posición del inodo index en la tabla de inodos
+func (sb *SuperBlock) InodeOffset(index int64) int64 {
+	return sb.S_inode_start + index*int64(sb.S_inode_size)
+}
+
+// BlockOffset posición del bloque index en la tabla de bloques
+func (sb *SuperBlock) BlockOffset(index int64) int64 {
+	return sb.S_block_start + index*int64(sb.S_block_size)
+}
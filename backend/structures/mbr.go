@@ -1,66 +1,75 @@
 package structures
 
 import (
-	"bytes"           // Paquete para manipulación de buffers
 	"encoding/binary" // Paquete para codificación y decodificación de datos binarios
 	"errors"
 	"fmt" // Paquete para formateo de E/S
-	"os"  // Paquete para funciones del sistema operativo
 	"strings"
 	"time"
 )
 
+// Número mágico y versión del formato de disco. Los discos v1 no tienen número mágico:
+// empiezan directamente con Mbr_size.
+const (
+	DiskMagic     = "MIA2"
+	FormatVersion = 2
+)
+
+// ErrLegacyFormat el disco está en formato v1 y hay que convertirlo con migrate
+var ErrLegacyFormat = errors.New("el disco usa el formato v1; conviértalo con migrate -path=<disco>")
+
 type MBR struct {
-	Mbr_size           int32        // Tamaño del MBR en bytes
-	Mbr_creation_date  float32      // Fecha y hora de creación del MBR
+	Mbr_magic          [4]byte      // Número mágico del formato (DiskMagic)
+	Mbr_version        int32        // Versión del formato (FormatVersion)
+	Mbr_size           int64        // Tamaño del disco en bytes
+	Mbr_creation_date  int64        // Fecha y hora de creación en nanosegundos Unix
 	Mbr_disk_signature int32        // Firma del disco
 	Mbr_disk_fit       [1]byte      // Tipo de ajuste
 	Mbr_partitions     [4]Partition // Particiones del MBR
+	// Total: 201 bytes
 }
 
-// SerializeMBR escribe la estructura MBR al inicio de un archivo binario
-func (mbr *MBR) Serialize(path string) error {
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
-	if err != nil {
-		return err
+// NewMBR crea el MBR de un disco vacío en el formato actual
+func NewMBR(size, creationDate int64, signature int32, fit byte) MBR {
+	mbr := MBR{
+		Mbr_version:        FormatVersion,
+		Mbr_size:           size,
+		Mbr_creation_date:  creationDate,
+		Mbr_disk_signature: signature,
+		Mbr_disk_fit:       [1]byte{fit},
 	}
-	defer file.Close()
+	copy(mbr.Mbr_magic[:], DiskMagic)
 
-	// Serializar la estructura MBR directamente en el archivo
-	err = binary.Write(file, binary.LittleEndian, mbr)
-	if err != nil {
-		return err
+	for i := range mbr.Mbr_partitions {
+		mbr.Mbr_partitions[i].Part_start = -1
+		mbr.Mbr_partitions[i].Part_status = [1]byte{'0'}
+		mbr.Mbr_partitions[i].Part_type = [1]byte{'0'}
+		mbr.Mbr_partitions[i].Part_fit = [1]byte{'0'}
 	}
-
-	return nil
+	return mbr
 }
 
-// DeserializeMBR lee la estructura MBR desde el inicio de un archivo binario
-func (mbr *MBR) Deserialize(path string) error {
-	file, err := os.Open(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+// IsCurrentFormat indica si el MBR tiene el número mágico y la versión actuales
+func (mbr *MBR) IsCurrentFormat() bool {
+	return string(mbr.Mbr_magic[:]) == DiskMagic && mbr.Mbr_version == FormatVersion
+}
 
-	// Obtener el tamaño de la estructura MBR
-	mbrSize := binary.Size(mbr)
-	if mbrSize <= 0 {
-		return fmt.Errorf("invalid MBR size: %d", mbrSize)
-	}
+// SerializeMBR escribe la estructura MBR al inicio del disco
+func (mbr *MBR) Serialize(dev Device) error {
+	return writeStruct(dev, 0, mbr)
+}
 
-	// Leer solo la cantidad de bytes que corresponden al tamaño de la estructura MBR
-	buffer := make([]byte, mbrSize)
-	_, err = file.Read(buffer)
-	if err != nil {
+// DeserializeMBR lee la estructura MBR desde el inicio del disco
+func (mbr *MBR) Deserialize(dev Device) error {
+	if err := readStruct(dev, 0, mbr); err != nil {
 		return err
 	}
 
-	// Deserializar los bytes leídos en la estructura MBR
-	reader := bytes.NewReader(buffer)
-	err = binary.Read(reader, binary.LittleEndian, mbr)
-	if err != nil {
-		return err
+	if !mbr.IsCurrentFormat() {
+		if string(mbr.Mbr_magic[:]) == DiskMagic {
+			return fmt.Errorf("versión de formato no soportada: %d", mbr.Mbr_version)
+		}
+		return ErrLegacyFormat
 	}
 
 	return nil
@@ -116,10 +125,39 @@ func (mbr *MBR) GetPartitionByID(id string) (*Partition, error) {
 	return nil, errors.New("partición no encontrada")
 }
 
+// Validate verifica que el MBR sea coherente con un archivo de fileSize bytes: el tamaño
+// registrado coincide y cada partición usada cabe en el disco sin solaparse con otra
+func (mbr *MBR) Validate(fileSize int64) error {
+	mbrSize := int64(binary.Size(mbr))
+	if int64(mbr.Mbr_size) != fileSize || fileSize <= mbrSize {
+		return fmt.Errorf("Mbr_size (%d) no coincide con el tamaño del archivo (%d)", mbr.Mbr_size, fileSize)
+	}
+
+	for i, partition := range mbr.Mbr_partitions {
+		if partition.Part_start == -1 {
+			continue
+		}
+		if partition.Part_type[0] != 'P' && partition.Part_type[0] != 'E' {
+			return fmt.Errorf("la partición %d tiene un tipo inválido (%q)", i, partition.Part_type[0])
+		}
+		start, end := int64(partition.Part_start), int64(partition.Part_start)+int64(partition.Part_size)
+		if partition.Part_size <= 0 || start < mbrSize || end > fileSize {
+			return fmt.Errorf("la partición %d ([%d, %d)) está fuera del disco", i, start, end)
+		}
+		for j := i + 1; j < len(mbr.Mbr_partitions); j++ {
+			other := mbr.Mbr_partitions[j]
+			if other.Part_start != -1 && start < int64(other.Part_start)+int64(other.Part_size) && int64(other.Part_start) < end {
+				return fmt.Errorf("las particiones %d y %d se solapan", i, j)
+			}
+		}
+	}
+	return nil
+}
+
 // Método para imprimir los valores del MBR
 func (mbr *MBR) PrintMBR() {
 	// Convertir Mbr_creation_date a time.Time
-	creationTime := time.Unix(int64(mbr.Mbr_creation_date), 0)
+	creationTime := time.Unix(0, mbr.Mbr_creation_date)
 
 	// Convertir Mbr_disk_fit a char
 	diskFit := rune(mbr.Mbr_disk_fit[0])
@@ -0,0 +1,75 @@
+package structures
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// MemDevice disco que vive solo en memoria: se pierde al cerrarlo o al terminar el
+// proceso. Sirve para demostraciones y para armar discos de prueba sin tocar archivos.
+type MemDevice struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+// NewMemDevice crea un disco en memoria de size bytes, lleno de ceros
+func NewMemDevice(size int64) *MemDevice {
+	return &MemDevice{data: make([]byte, size)}
+}
+
+// Size tamaño del disco en bytes
+func (d *MemDevice) Size() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return int64(len(d.data))
+}
+
+func (d *MemDevice) ReadAt(p []byte, off int64) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if off < 0 {
+		return 0, errors.New("offset negativo")
+	}
+	if off >= int64(len(d.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, d.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (d *MemDevice) WriteAt(p []byte, off int64) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if off < 0 {
+		return 0, errors.New("offset negativo")
+	}
+	if d.data == nil {
+		return 0, errors.New("el disco en memoria está cerrado")
+	}
+
+	// El tamaño se fija al crearlo (y cuenta para MIA_MEM_LIMIT_MB): no se agranda
+	if end := off + int64(len(p)); end > int64(len(d.data)) {
+		return 0, fmt.Errorf("escritura fuera del disco en memoria: [%d, %d) supera sus %d bytes", off, end, len(d.data))
+	}
+	return copy(d.data[off:], p), nil
+}
+
+// Flush no hace nada: los cambios ya están en memoria
+func (d *MemDevice) Flush() error {
+	return nil
+}
+
+// Close libera el contenido del disco
+func (d *MemDevice) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.data = nil
+	return nil
+}
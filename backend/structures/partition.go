@@ -6,8 +6,8 @@ type Partition struct {
 	Part_status      [1]byte  // Estado de la partición
 	Part_type        [1]byte  // Tipo de partición
 	Part_fit         [1]byte  // Ajuste de la partición
-	Part_start       int32    // Byte de inicio de la partición
-	Part_size        int32    // Tamaño de la partición
+	Part_start       int64    // Byte de inicio de la partición
+	Part_size        int64    // Tamaño de la partición
 	Part_name        [16]byte // Nombre de la partición
 	Part_correlative int32    // Correlativo de la partición
 	Part_id          [4]byte  // ID de la partición
@@ -31,10 +31,10 @@ func (p *Partition) CreatePartition(partStart, partSize int, partType, partFit,
 	p.Part_status[0] = '0'
 
 	// Asignar el byte de inicio de la partición
-	p.Part_start = int32(partStart)
+	p.Part_start = int64(partStart)
 
 	// Asignar el tamaño de la partición
-	p.Part_size = int32(partSize)
+	p.Part_size = int64(partSize)
 
 	// Asignar el tipo de partición
 	if len(partType) > 0 {
@@ -1,10 +1,7 @@
 package structures
 
 import (
-	"bytes"
-	"encoding/binary"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 )
@@ -15,87 +12,38 @@ type SuperBlock struct {
 	S_blocks_count      int32
 	S_free_inodes_count int32
 	S_free_blocks_count int32
-	S_mtime             float32
-	S_umtime            float32
+	S_mtime             int64 // Nanosegundos Unix
+	S_umtime            int64 // Nanosegundos Unix
 	S_mnt_count         int32
 	S_magic             int32
 	S_inode_size        int32
 	S_block_size        int32
-	S_first_ino         int32
-	S_first_blo         int32
-	S_bm_inode_start    int32
-	S_bm_block_start    int32
-	S_inode_start       int32
-	S_block_start       int32
-	// Total: 68 bytes
+	S_first_ino         int64
+	S_first_blo         int64
+	S_bm_inode_start    int64
+	S_bm_block_start    int64
+	S_inode_start       int64
+	S_block_start       int64
+	S_features          int32 // Banderas de características opcionales del formato v2
+	// Total: 104 bytes
 }
 
-// Serialize escribe la estructura SuperBlock en un archivo binario en la posición especificada
-func (sb *SuperBlock) Serialize(path string, offset int64) error {
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	// Mover el puntero del archivo a la posición especificada
-	_, err = file.Seek(offset, 0)
-	if err != nil {
-		return err
-	}
-
-	// Serializar la estructura SuperBlock directamente en el archivo
-	err = binary.Write(file, binary.LittleEndian, sb)
-	if err != nil {
-		return err
-	}
-
-	return nil
+// Serialize escribe la estructura SuperBlock en el disco en la posición especificada
+func (sb *SuperBlock) Serialize(dev Device, offset int64) error {
+	return writeStruct(dev, offset, sb)
 }
 
-// Deserialize lee la estructura SuperBlock desde un archivo binario en la posición especificada
-func (sb *SuperBlock) Deserialize(path string, offset int64) error {
-	file, err := os.Open(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	// Mover el puntero del archivo a la posición especificada
-	_, err = file.Seek(offset, 0)
-	if err != nil {
-		return err
-	}
-
-	// Obtener el tamaño de la estructura SuperBlock
-	sbSize := binary.Size(sb)
-	if sbSize <= 0 {
-		return fmt.Errorf("invalid SuperBlock size: %d", sbSize)
-	}
-
-	// Leer solo la cantidad de bytes que corresponden al tamaño de la estructura SuperBlock
-	buffer := make([]byte, sbSize)
-	_, err = file.Read(buffer)
-	if err != nil {
-		return err
-	}
-
-	// Deserializar los bytes leídos en la estructura SuperBlock
-	reader := bytes.NewReader(buffer)
-	err = binary.Read(reader, binary.LittleEndian, sb)
-	if err != nil {
-		return err
-	}
-
-	return nil
+// Deserialize lee la estructura SuperBlock del disco en la posición especificada
+func (sb *SuperBlock) Deserialize(dev Device, offset int64) error {
+	return readStruct(dev, offset, sb)
 }
 
 // PrintSuperBlock imprime los valores de la estructura SuperBlock
 func (sb *SuperBlock) Print() {
 	// Convertir el tiempo de montaje a una fecha
-	mountTime := time.Unix(int64(sb.S_mtime), 0)
+	mountTime := time.Unix(0, sb.S_mtime)
 	// Convertir el tiempo de desmontaje a una fecha
-	unmountTime := time.Unix(int64(sb.S_umtime), 0)
+	unmountTime := time.Unix(0, sb.S_umtime)
 
 	fmt.Printf("Filesystem Type: %d\n", sb.S_filesystem_type)
 	fmt.Printf("Inodes Count: %d\n", sb.S_inodes_count)
@@ -114,17 +62,18 @@ func (sb *SuperBlock) Print() {
 	fmt.Printf("Bitmap Block Start: %d\n", sb.S_bm_block_start)
 	fmt.Printf("Inode Start: %d\n", sb.S_inode_start)
 	fmt.Printf("Block Start: %d\n", sb.S_block_start)
+	fmt.Printf("Features: %d\n", sb.S_features)
 }
 
 // Imprimir inodos
-func (sb *SuperBlock) PrintInodes(path string) error {
+func (sb *SuperBlock) PrintInodes(dev Device) error {
 	// Imprimir inodos
 	fmt.Println("\nInodos\n----------------")
 	// Iterar sobre cada inodo
 	for i := int32(0); i < sb.S_inodes_count; i++ {
 		inode := &Inode{}
 		// Deserializar el inodo
-		err := inode.Deserialize(path, int64(sb.S_inode_start+(i*sb.S_inode_size)))
+		err := inode.Deserialize(dev, sb.InodeOffset(int64(i)))
 		if err != nil {
 			return err
 		}
@@ -137,14 +86,14 @@ func (sb *SuperBlock) PrintInodes(path string) error {
 }
 
 // Impriir bloques
-func (sb *SuperBlock) PrintBlocks(path string) error {
+func (sb *SuperBlock) PrintBlocks(dev Device) error {
 	// Imprimir bloques
 	fmt.Println("\nBloques\n----------------")
 	// Iterar sobre cada inodo
 	for i := int32(0); i < sb.S_inodes_count; i++ {
 		inode := &Inode{}
 		// Deserializar el inodo
-		err := inode.Deserialize(path, int64(sb.S_inode_start+(i*sb.S_inode_size)))
+		err := inode.Deserialize(dev, sb.InodeOffset(int64(i)))
 		if err != nil {
 			return err
 		}
@@ -158,7 +107,7 @@ func (sb *SuperBlock) PrintBlocks(path string) error {
 			if inode.I_type[0] == '0' {
 				block := &FolderBlock{}
 				// Deserializar el bloque
-				err := block.Deserialize(path, int64(sb.S_block_start+(blockIndex*sb.S_block_size))) // 64 porque es el tamaño de un bloque
+				err := block.Deserialize(dev, sb.BlockOffset(int64(blockIndex)))
 				if err != nil {
 					return err
 				}
@@ -171,7 +120,7 @@ func (sb *SuperBlock) PrintBlocks(path string) error {
 			} else if inode.I_type[0] == '1' {
 				block := &FileBlock{}
 				// Deserializar el bloque
-				err := block.Deserialize(path, int64(sb.S_block_start+(blockIndex*sb.S_block_size))) // 64 porque es el tamaño de un bloque
+				err := block.Deserialize(dev, sb.BlockOffset(int64(blockIndex)))
 				if err != nil {
 					return err
 				}
@@ -188,12 +137,12 @@ func (sb *SuperBlock) PrintBlocks(path string) error {
 }
 
 // Get users.txt block
-func (sb *SuperBlock) GetUsersBlock(path string) (*FileBlock, error) {
+func (sb *SuperBlock) GetUsersBlock(dev Device) (*FileBlock, error) {
 	// Ir al inodo 1
 	inode := &Inode{}
 
 	// Deserializar el inodo
-	err := inode.Deserialize(path, int64(sb.S_inode_start+(1*sb.S_inode_size))) // 1 porque es el inodo 1
+	err := inode.Deserialize(dev, sb.InodeOffset(1)) // 1 porque es el inodo 1
 	if err != nil {
 		return nil, err
 	}
@@ -208,7 +157,7 @@ func (sb *SuperBlock) GetUsersBlock(path string) (*FileBlock, error) {
 		if inode.I_type[0] == '1' {
 			block := &FileBlock{}
 			// Deserializar el bloque
-			err := block.Deserialize(path, int64(sb.S_block_start+(blockIndex*sb.S_block_size))) // 64 porque es el tamaño de un bloque
+			err := block.Deserialize(dev, sb.BlockOffset(int64(blockIndex)))
 			if err != nil {
 				return nil, err
 			}
@@ -222,18 +171,18 @@ func (sb *SuperBlock) GetUsersBlock(path string) (*FileBlock, error) {
 }
 
 // CreateFolder crea una carpeta en el sistema de archivos
-func (sb *SuperBlock) CreateFolder(path string, parentsDir []string, destDir string) error {
+func (sb *SuperBlock) CreateFolder(dev Device, parentsDir []string, destDir string) error {
 
 	// Validar el sistema de archivos
 	if sb.S_filesystem_type == 3 {
 		// Si parentsDir está vacío, solo trabajar con el primer inodo que sería el raíz "/"
 		if len(parentsDir) == 0 {
-			return sb.createFolderInInodeExt3(path, 0, parentsDir, destDir)
+			return sb.createFolderInInodeExt3(dev, 0, parentsDir, destDir)
 		}
 
 		// Iterar sobre cada inodo ya que se necesita buscar el inodo padre
 		for i := int32(0); i < sb.S_inodes_count; i++ {
-			err := sb.createFolderInInodeExt3(path, i, parentsDir, destDir)
+			err := sb.createFolderInInodeExt3(dev, i, parentsDir, destDir)
 			if err != nil {
 				return err
 			}
@@ -241,12 +190,12 @@ func (sb *SuperBlock) CreateFolder(path string, parentsDir []string, destDir str
 	} else {
 		// Si parentsDir está vacío, solo trabajar con el primer inodo que sería el raíz "/"
 		if len(parentsDir) == 0 {
-			return sb.createFolderInInodeExt2(path, 0, parentsDir, destDir)
+			return sb.createFolderInInodeExt2(dev, 0, parentsDir, destDir)
 		}
 
 		// Iterar sobre cada inodo ya que se necesita buscar el inodo padre
 		for i := int32(0); i < sb.S_inodes_count; i++ {
-			err := sb.createFolderInInodeExt2(path, i, parentsDir, destDir)
+			err := sb.createFolderInInodeExt2(dev, i, parentsDir, destDir)
 			if err != nil {
 				return err
 			}
@@ -256,7 +205,7 @@ func (sb *SuperBlock) CreateFolder(path string, parentsDir []string, destDir str
 	return nil
 }
 
-func (sb *SuperBlock) CreateFile(path string, parentsDir []string, destFile string, size int, cont []string) error {
+func (sb *SuperBlock) CreateFile(dev Device, parentsDir []string, destFile string, size int, cont []string) error {
 	// 1) Preparar contenido
 	var content string
 	if len(cont) > 0 {
@@ -273,21 +222,21 @@ func (sb *SuperBlock) CreateFile(path string, parentsDir []string, destFile stri
 		// Sin padres: crear directamente en raíz (inodo 0)
 		parentInodeIndex = 0
 	} else {
-		parentInodeIndex, err = sb.resolveParentInode(path, parentsDir)
+		parentInodeIndex, err = sb.resolveParentInode(dev, parentsDir)
 		if err != nil {
 			return fmt.Errorf("directorio padre no encontrado: %v", err)
 		}
 	}
 
 	// 3) Crear el archivo en el inodo padre resuelto
-	if err := sb.createFileInInodeExt2(path, parentInodeIndex, parentsDir, destFile, size, content); err != nil {
+	if err := sb.createFileInInodeExt2(dev, parentInodeIndex, parentsDir, destFile, size, content); err != nil {
 		return fmt.Errorf("error al crear el archivo: %v", err)
 	}
 	return nil
 }
 
 // resolveParentInode recorre la ruta padre desde la raíz y devuelve el índice de inodo del último directorio
-func (sb *SuperBlock) resolveParentInode(path string, parentsDir []string) (int32, error) {
+func (sb *SuperBlock) resolveParentInode(dev Device, parentsDir []string) (int32, error) {
 	current := int32(0) // empezar en raíz
 
 	for _, dirName := range parentsDir {
@@ -295,7 +244,7 @@ func (sb *SuperBlock) resolveParentInode(path string, parentsDir []string) (int3
 
 		// Deserializar inodo actual
 		inode := &Inode{}
-		if err := inode.Deserialize(path, int64(sb.S_inode_start+(current*sb.S_inode_size))); err != nil {
+		if err := inode.Deserialize(dev, sb.InodeOffset(int64(current))); err != nil {
 			return -1, err
 		}
 		// Debe ser directorio
@@ -309,7 +258,7 @@ func (sb *SuperBlock) resolveParentInode(path string, parentsDir []string) (int3
 				break
 			}
 			block := &FolderBlock{}
-			if err := block.Deserialize(path, int64(sb.S_block_start+(blockIndex*sb.S_block_size))); err != nil {
+			if err := block.Deserialize(dev, sb.BlockOffset(int64(blockIndex))); err != nil {
 				continue
 			}
 			for _, entry := range block.B_content {
@@ -334,10 +283,10 @@ func (sb *SuperBlock) resolveParentInode(path string, parentsDir []string) (int3
 }
 
 // Método auxiliar para verificar si un inodo es el directorio padre
-func (sb *SuperBlock) isParentDirectory(path string, inodeIndex int32, parentsDir []string) bool {
+func (sb *SuperBlock) isParentDirectory(dev Device, inodeIndex int32, parentsDir []string) bool {
 	// Deserializar el inodo
 	inode := &Inode{}
-	err := inode.Deserialize(path, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+	err := inode.Deserialize(dev, sb.InodeOffset(int64(inodeIndex)))
 	if err != nil {
 		return false
 	}
@@ -355,7 +304,7 @@ func (sb *SuperBlock) isParentDirectory(path string, inodeIndex int32, parentsDi
 
 		// Deserializar el bloque de directorio
 		block := &FolderBlock{}
-		err := block.Deserialize(path, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
+		err := block.Deserialize(dev, sb.BlockOffset(int64(blockIndex)))
 		if err != nil {
 			continue
 		}
@@ -376,17 +325,17 @@ func (sb *SuperBlock) isParentDirectory(path string, inodeIndex int32, parentsDi
 }
 
 // Método para crear el archivo en un inodo específico
-func (sb *SuperBlock) createFileInInodeExt2(path string, inodeIndex int32, parentsDir []string, destFile string, size int, content string) error {
+func (sb *SuperBlock) createFileInInodeExt2(dev Device, inodeIndex int32, parentsDir []string, destFile string, size int, content string) error {
 	// Deserializar el inodo padre
 	parentInode := &Inode{}
-	err := parentInode.Deserialize(path, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+	err := parentInode.Deserialize(dev, sb.InodeOffset(int64(inodeIndex)))
 	if err != nil {
 		return err
 	}
 
 	// Deserializar el bloque padre
 	parentBlock := &FolderBlock{}
-	err = parentBlock.Deserialize(path, int64(sb.S_block_start+(parentInode.I_block[0]*sb.S_block_size)))
+	err = parentBlock.Deserialize(dev, sb.BlockOffset(int64(parentInode.I_block[0])))
 	if err != nil {
 		return err
 	}
@@ -396,22 +345,22 @@ func (sb *SuperBlock) createFileInInodeExt2(path string, inodeIndex int32, paren
 		I_uid:   1,
 		I_gid:   1,
 		I_size:  int32(len(content)),
-		I_atime: float32(time.Now().Unix()),
-		I_ctime: float32(time.Now().Unix()),
-		I_mtime: float32(time.Now().Unix()),
+		I_atime: time.Now().UnixNano(),
+		I_ctime: time.Now().UnixNano(),
+		I_mtime: time.Now().UnixNano(),
 		I_block: [15]int32{sb.S_blocks_count, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
 		I_type:  [1]byte{'1'}, // Tipo archivo
 		I_perm:  [3]byte{'7', '7', '7'},
 	}
 
 	// Actualizar bitmap de inodos
-	err = sb.UpdateBitmapInode(path)
+	err = sb.UpdateBitmapInode(dev)
 	if err != nil {
 		return err
 	}
 
 	// Serializar el inodo del archivo
-	err = fileInode.Serialize(path, int64(sb.S_first_ino))
+	err = fileInode.Serialize(dev, int64(sb.S_first_ino))
 	if err != nil {
 		return err
 	}
@@ -419,7 +368,7 @@ func (sb *SuperBlock) createFileInInodeExt2(path string, inodeIndex int32, paren
 	// Actualizar contadores de inodos
 	sb.S_inodes_count++
 	sb.S_free_inodes_count--
-	sb.S_first_ino += sb.S_inode_size
+	sb.S_first_ino += int64(sb.S_inode_size)
 
 	// Crear bloque de archivo
 	fileBlock := &FileBlock{
@@ -428,13 +377,13 @@ func (sb *SuperBlock) createFileInInodeExt2(path string, inodeIndex int32, paren
 	copy(fileBlock.B_content[:], content)
 
 	// Serializar bloque de archivo
-	err = fileBlock.Serialize(path, int64(sb.S_first_blo))
+	err = fileBlock.Serialize(dev, int64(sb.S_first_blo))
 	if err != nil {
 		return err
 	}
 
 	// Actualizar bitmap de bloques
-	err = sb.UpdateBitmapBlock(path)
+	err = sb.UpdateBitmapBlock(dev)
 	if err != nil {
 		return err
 	}
@@ -442,7 +391,7 @@ func (sb *SuperBlock) createFileInInodeExt2(path string, inodeIndex int32, paren
 	// Actualizar contadores de bloques
 	sb.S_blocks_count++
 	sb.S_free_blocks_count--
-	sb.S_first_blo += sb.S_block_size
+	sb.S_first_blo += int64(sb.S_block_size)
 
 	// Actualizar bloque padre para añadir referencia al nuevo archivo
 	for i := 2; i < len(parentBlock.B_content); i++ {
@@ -454,7 +403,7 @@ func (sb *SuperBlock) createFileInInodeExt2(path string, inodeIndex int32, paren
 	}
 
 	// Serializar bloque padre actualizado
-	err = parentBlock.Serialize(path, int64(sb.S_block_start+(parentInode.I_block[0]*sb.S_block_size)))
+	err = parentBlock.Serialize(dev, sb.BlockOffset(int64(parentInode.I_block[0])))
 	if err != nil {
 		return err
 	}
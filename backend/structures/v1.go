@@ -0,0 +1,180 @@
+package structures
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// Estructuras del formato v1 (sin número mágico en el MBR): tamaños y offsets de 32 bits
+// y fechas en segundos float32. Solo se usan para leer discos viejos y convertirlos
+// con migrate; los comandos trabajan siempre con el formato actual.
+
+type MBRV1 struct {
+	Mbr_size           int32
+	Mbr_creation_date  float32
+	Mbr_disk_signature int32
+	Mbr_disk_fit       [1]byte
+	Mbr_partitions     [4]PartitionV1
+	// Total: 153 bytes
+}
+
+type PartitionV1 struct {
+	Part_status      [1]byte
+	Part_type        [1]byte
+	Part_fit         [1]byte
+	Part_start       int32
+	Part_size        int32
+	Part_name        [16]byte
+	Part_correlative int32
+	Part_id          [4]byte
+	// Total: 35 bytes
+}
+
+type EBRV1 struct {
+	Part_mount [1]byte
+	Part_fit   [1]byte
+	Part_start int32
+	Part_size  int32
+	Part_next  int32
+	Part_name  [16]byte
+	// Total: 30 bytes
+}
+
+type SuperBlockV1 struct {
+	S_filesystem_type   int32
+	S_inodes_count      int32
+	S_blocks_count      int32
+	S_free_inodes_count int32
+	S_free_blocks_count int32
+	S_mtime             float32
+	S_umtime            float32
+	S_mnt_count         int32
+	S_magic             int32
+	S_inode_size        int32
+	S_block_size        int32
+	S_first_ino         int32
+	S_first_blo         int32
+	S_bm_inode_start    int32
+	S_bm_block_start    int32
+	S_inode_start       int32
+	S_block_start       int32
+	// Total: 68 bytes
+}
+
+type InodeV1 struct {
+	I_uid   int32
+	I_gid   int32
+	I_size  int32
+	I_atime float32
+	I_ctime float32
+	I_mtime float32
+	I_block [15]int32
+	I_type  [1]byte
+	I_perm  [3]byte
+	// Total: 88 bytes
+}
+
+type JournalV1 struct {
+	J_count   int32
+	J_content InformationV1
+	// Total: 114 bytes
+}
+
+type InformationV1 struct {
+	I_operation [10]byte
+	I_path      [32]byte
+	I_content   [64]byte
+	I_date      float32
+	// Total: 110 bytes
+}
+
+// ReadV1 lee una estructura v1 (o cualquier estructura de tamaño fijo) en offset
+func ReadV1(r io.ReaderAt, offset int64, data any) error {
+	return binary.Read(io.NewSectionReader(r, offset, int64(binary.Size(data))), binary.LittleEndian, data)
+}
+
+// secondsToNanos convierte una fecha v1 (segundos en float32) a nanosegundos
+func secondsToNanos(seconds float32) int64 {
+	return int64(seconds) * 1e9
+}
+
+// ToV2 convierte el MBR v1 al formato actual; las particiones conservan sus offsets
+func (m *MBRV1) ToV2() MBR {
+	mbr := NewMBR(int64(m.Mbr_size), secondsToNanos(m.Mbr_creation_date), m.Mbr_disk_signature, m.Mbr_disk_fit[0])
+	for i := range m.Mbr_partitions {
+		mbr.Mbr_partitions[i] = m.Mbr_partitions[i].ToV2()
+	}
+	return mbr
+}
+
+func (p *PartitionV1) ToV2() Partition {
+	return Partition{
+		Part_status:      p.Part_status,
+		Part_type:        p.Part_type,
+		Part_fit:         p.Part_fit,
+		Part_start:       int64(p.Part_start),
+		Part_size:        int64(p.Part_size),
+		Part_name:        p.Part_name,
+		Part_correlative: p.Part_correlative,
+		Part_id:          p.Part_id,
+	}
+}
+
+func (e *EBRV1) ToV2() EBR {
+	return EBR{
+		Part_mount: e.Part_mount,
+		Part_fit:   e.Part_fit,
+		Part_start: int64(e.Part_start),
+		Part_size:  int64(e.Part_size),
+		Part_next:  int64(e.Part_next),
+		Part_name:  e.Part_name,
+	}
+}
+
+func (s *SuperBlockV1) ToV2() SuperBlock {
+	return SuperBlock{
+		S_filesystem_type:   s.S_filesystem_type,
+		S_inodes_count:      s.S_inodes_count,
+		S_blocks_count:      s.S_blocks_count,
+		S_free_inodes_count: s.S_free_inodes_count,
+		S_free_blocks_count: s.S_free_blocks_count,
+		S_mtime:             secondsToNanos(s.S_mtime),
+		S_umtime:            secondsToNanos(s.S_umtime),
+		S_mnt_count:         s.S_mnt_count,
+		S_magic:             s.S_magic,
+		S_inode_size:        s.S_inode_size,
+		S_block_size:        s.S_block_size,
+		S_first_ino:         int64(s.S_first_ino),
+		S_first_blo:         int64(s.S_first_blo),
+		S_bm_inode_start:    int64(s.S_bm_inode_start),
+		S_bm_block_start:    int64(s.S_bm_block_start),
+		S_inode_start:       int64(s.S_inode_start),
+		S_block_start:       int64(s.S_block_start),
+	}
+}
+
+func (i *InodeV1) ToV2() Inode {
+	return Inode{
+		I_uid:   i.I_uid,
+		I_gid:   i.I_gid,
+		I_size:  i.I_size,
+		I_atime: secondsToNanos(i.I_atime),
+		I_ctime: secondsToNanos(i.I_ctime),
+		I_mtime: secondsToNanos(i.I_mtime),
+		I_block: i.I_block,
+		I_type:  i.I_type,
+		I_perm:  i.I_perm,
+	}
+}
+
+func (j *JournalV1) ToV2() Journal {
+	return Journal{
+		J_count: j.J_count,
+		J_content: Information{
+			I_operation: j.J_content.I_operation,
+			I_path:      j.J_content.I_path,
+			I_content:   j.J_content.I_content,
+			I_date:      secondsToNanos(j.J_content.I_date),
+		},
+	}
+}
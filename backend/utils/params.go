@@ -0,0 +1,44 @@
+package utils
+
+import "sort"
+
+// Param parámetro de un comando tal como lo produce el analizador léxico
+type Param struct {
+	Key        string // Nombre en minúsculas, sin el guion inicial
+	Value      string // Valor sin comillas
+	IsFlag     bool   // true si se escribió sin valor (ej: -p, -r)
+	Positional bool   // true si se escribió sin guion (la clave es argN)
+	Position   int    // Posición del parámetro en la línea (desde 0)
+}
+
+// Params parámetros de un comando indexados por nombre en minúsculas
+type Params map[string]Param
+
+// Has indica si el parámetro fue proporcionado
+func (p Params) Has(key string) bool {
+	_, ok := p[key]
+	return ok
+}
+
+// Get devuelve el valor del parámetro y si fue proporcionado
+func (p Params) Get(key string) (string, bool) {
+	param, ok := p[key]
+	return param.Value, ok
+}
+
+// Value devuelve el valor del parámetro o "" si no fue proporcionado
+func (p Params) Value(key string) string {
+	return p[key].Value
+}
+
+// List devuelve los parámetros en el orden en que se escribieron
+func (p Params) List() []Param {
+	list := make([]Param, 0, len(p))
+	for _, param := range p {
+		list = append(list, param)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Position < list[j].Position
+	})
+	return list
+}
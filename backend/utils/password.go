@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// Prefijo de versión de las contraseñas con hash guardadas en users.txt.
+// Formato: $1$<sal>$<hash>, ambos en base64 sin relleno (sin comas, para no romper el CSV)
+const passwordHashV1 = "$1$"
+
+// Cantidad de bytes de sal y rondas de SHA-256 del formato v1
+const (
+	passwordSaltSize   = 8
+	passwordIterations = 4096
+)
+
+// HashPassword genera el hash con sal de una contraseña en el formato versionado actual
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, passwordSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("error generando la sal: %w", err)
+	}
+
+	return passwordHashV1 + encodeHashPart(salt) + "$" + encodeHashPart(hashPasswordV1(salt, password)), nil
+}
+
+// VerifyPassword compara una contraseña contra el valor guardado en users.txt.
+// El segundo valor indica si el valor guardado está en texto plano y debe actualizarse.
+func VerifyPassword(stored string, password string) (bool, bool) {
+	if !IsHashedPassword(stored) {
+		// Entradas anteriores al formato versionado: texto plano
+		ok := subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+		return ok, ok
+	}
+
+	parts := strings.Split(strings.TrimPrefix(stored, passwordHashV1), "$")
+	if len(parts) != 2 {
+		return false, false
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[0])
+	if err != nil {
+		return false, false
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return false, false
+	}
+
+	return subtle.ConstantTimeCompare(expected, hashPasswordV1(salt, password)) == 1, false
+}
+
+// IsHashedPassword indica si el valor guardado usa el formato con hash
+func IsHashedPassword(stored string) bool {
+	return strings.HasPrefix(stored, passwordHashV1)
+}
+
+// hashPasswordV1 aplica SHA-256 iterado sobre sal + contraseña
+func hashPasswordV1(salt []byte, password string) []byte {
+	sum := sha256.Sum256(append(append([]byte{}, salt...), password...))
+	for i := 1; i < passwordIterations; i++ {
+		sum = sha256.Sum256(append(sum[:], salt...))
+	}
+	return sum[:]
+}
+
+func encodeHashPart(b []byte) string {
+	return base64.RawStdEncoding.EncodeToString(b)
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -30,42 +31,6 @@ func ConvertToBytes(size int, unit string) (int, error) {
 	}
 }
 
-// Lista con todo el abecedario
-var alphabet = []string{
-	"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L", "M",
-	"N", "O", "P", "Q", "R", "S", "T", "U", "V", "W", "X", "Y", "Z",
-}
-
-// Mapa para almacenar la asignación de letras a los diferentes paths
-var pathToLetter = make(map[string]string)
-
-// Mapa para almacenar el contador de particiones por path
-var pathToPartitionCount = make(map[string]int)
-
-// Índice para la siguiente letra disponible en el abecedario
-var nextLetterIndex = 0
-
-// GetLetter obtiene la letra asignada a un path y el siguiente índice de partición
-func GetLetterAndPartitionCorrelative(path string) (string, int, error) {
-	// Asignar una letra al path si no tiene una asignada
-	if _, exists := pathToLetter[path]; !exists {
-		if nextLetterIndex < len(alphabet) {
-			pathToLetter[path] = alphabet[nextLetterIndex]
-			pathToPartitionCount[path] = 0 // Inicializar el contador de particiones
-			nextLetterIndex++
-		} else {
-			fmt.Println("Error: no hay más letras disponibles para asignar")
-			return "", 0, errors.New("no hay más letras disponibles para asignar")
-		}
-	}
-
-	// Incrementar y obtener el siguiente índice de partición para este path
-	pathToPartitionCount[path]++
-	nextIndex := pathToPartitionCount[path]
-
-	return pathToLetter[path], nextIndex, nil
-}
-
 // createParentDirs crea las carpetas padre si no existen
 func CreateParentDirs(path string) error {
 	dir := filepath.Dir(path)
@@ -137,3 +102,20 @@ func SplitStringIntoChunks(s string) []string {
 	}
 	return chunks
 }
+
+// Tamaño de los bloques con los que se escriben ceros en los discos
+const zeroChunkSize = 1024 * 1024
+
+// ZeroFill llena con ceros [start, start+length) escribiendo por bloques de 1 MB, sin
+// reservar un buffer del tamaño completo
+func ZeroFill(w io.WriterAt, start, length int64) error {
+	zeros := make([]byte, min(zeroChunkSize, max(length, 0)))
+	for done := int64(0); done < length; {
+		n := min(int64(len(zeros)), length-done)
+		if _, err := w.WriteAt(zeros[:n], start+done); err != nil {
+			return err
+		}
+		done += n
+	}
+	return nil
+}
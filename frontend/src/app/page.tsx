@@ -3,13 +3,14 @@ import { useState } from "react";
 import InputTerminal from "@/components/InputTerminal";
 import OutputTerminal from "@/components/OutputTerminal";
 import FileUpload from "@/components/FileUpload";
-import { executeCommands } from "@/services/api";
+import { executeCommandsStream } from "@/services/api";
 
 export default function Home() {
   // === Estados generales ===
   const [input, setInput] = useState("");
   const [output, setOutput] = useState("");
   const [isLoading, setIsLoading] = useState(false);
+  const [dryRun, setDryRun] = useState(false);
   const [activeTab, setActiveTab] = useState<
     "ejecucion" | "login" | "explorador"
   >("ejecucion");
@@ -40,19 +41,26 @@ export default function Home() {
     }
 
     setIsLoading(true);
+    setOutput("");
     try {
-      const lines = input.split("\n");
       let outputResult = "";
 
-      for (const line of lines) {
-        const trimmedLine = line.trim();
-        if (trimmedLine && !trimmedLine.startsWith("#")) {
-          const result = await executeCommands(trimmedLine);
-          if (result) outputResult += result + "\n";
-        }
+      // Cada comando se muestra en la consola apenas el backend lo termina
+      const summary = await executeCommandsStream(
+        input,
+        (event) => {
+          const text =
+            event.status === "error" ? `Error: ${event.error}` : event.output;
+          if (text) outputResult += text + "\n";
+          setOutput(outputResult);
+        },
+        dryRun
+      );
+
+      if (summary.total === 0) {
+        outputResult = "No se ejecutó ningún comando";
+        setOutput(outputResult);
       }
-
-      setOutput(outputResult);
       parseOutputToFilesystem(outputResult); // 🔄 Actualiza explorador
     } catch (error) {
       setOutput(error instanceof Error ? error.message : "Error desconocido");
@@ -323,6 +331,16 @@ if (line.startsWith("MKFILE: Archivo creado exitosamente")) {
                 📂 Cargar Archivo
               </label>
 
+              {/* En dry-run los comandos destructivos solo informan lo que harían */}
+              <label className="flex items-center gap-2 text-sm text-gray-700 dark:text-gray-300 cursor-pointer">
+                <input
+                  type="checkbox"
+                  checked={dryRun}
+                  onChange={(e) => setDryRun(e.target.checked)}
+                />
+                Dry-run
+              </label>
+
               <button
                 onClick={handleClear}
                 className="px-4 py-2 rounded-lg bg-red-500 text-white hover:bg-red-600 transition-colors duration-200 shadow-sm flex items-center gap-2"
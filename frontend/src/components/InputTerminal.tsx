@@ -1,13 +1,141 @@
-import React from "react";
+import React, { useEffect, useRef, useState } from "react";
+import { CommandInfo, getCommands } from "@/services/api";
 
 interface InputTerminalProps {
   value: string;
   onChange: (value: string) => void;
 }
 
+interface Suggestion {
+  label: string; // Texto mostrado en la lista
+  insert: string; // Texto que reemplaza a la palabra actual
+  detail: string; // Descripción breve
+}
+
+// Calcula las sugerencias para la palabra que está antes del cursor
+const buildSuggestions = (
+  commands: CommandInfo[],
+  text: string,
+  cursor: number
+): { start: number; items: Suggestion[] } => {
+  const lineStart = text.lastIndexOf("\n", cursor - 1) + 1;
+  const beforeCursor = text.slice(lineStart, cursor);
+  if (beforeCursor.trimStart().startsWith("#")) return { start: cursor, items: [] };
+
+  const wordMatch = beforeCursor.match(/\S*$/);
+  const word = wordMatch ? wordMatch[0] : "";
+  const start = cursor - word.length;
+  const previous = beforeCursor.slice(0, beforeCursor.length - word.length).trim();
+
+  // Primera palabra de la línea: nombre del comando
+  if (previous === "") {
+    if (word === "") return { start, items: [] };
+    const items = commands
+      .filter((c) => c.name.startsWith(word.toLowerCase()))
+      .map((c) => ({ label: c.name, insert: c.name + " ", detail: c.description }));
+    return { start, items };
+  }
+
+  const command = commands.find(
+    (c) => c.name === previous.split(/\s+/)[0].toLowerCase()
+  );
+  if (!command || !word.startsWith("-")) return { start, items: [] };
+
+  const eq = word.indexOf("=");
+
+  // Después del "=": valores permitidos del parámetro
+  if (eq !== -1) {
+    const key = word.slice(1, eq).toLowerCase();
+    const param = command.params.find((p) => p.name === key);
+    const partial = word.slice(eq + 1).toLowerCase();
+    const items = (param?.allowed ?? [])
+      .filter((v) => v.toLowerCase().startsWith(partial))
+      .map((v) => ({
+        label: v,
+        insert: `-${key}=${v} `,
+        detail: v === param?.default ? "por defecto" : "",
+      }));
+    return { start, items };
+  }
+
+  // Nombre del parámetro, omitiendo los que ya se escribieron en la línea
+  const used = new Set(
+    previous
+      .split(/\s+/)
+      .filter((w) => w.startsWith("-"))
+      .map((w) => w.slice(1).split("=")[0].toLowerCase())
+  );
+  const items = command.params
+    .filter((p) => !p.positional && (p.numbered || !used.has(p.name)))
+    .filter((p) => p.name.startsWith(word.slice(1).toLowerCase()))
+    .map((p) => {
+      const name = p.numbered ? `${p.name}1` : p.name;
+      return {
+        label: `-${name}`,
+        insert: p.type === "flag" ? `-${name} ` : `-${name}=`,
+        detail: p.description + (p.required ? " (obligatorio)" : ""),
+      };
+    });
+  return { start, items };
+};
+
 const InputTerminal = ({ value, onChange }: InputTerminalProps) => {
+  const textareaRef = useRef<HTMLTextAreaElement>(null);
+  const [commands, setCommands] = useState<CommandInfo[]>([]);
+  const [suggestions, setSuggestions] = useState<Suggestion[]>([]);
+  const [wordStart, setWordStart] = useState(0);
+  const [selected, setSelected] = useState(0);
+
+  // Los comandos se cargan una sola vez desde el backend
+  useEffect(() => {
+    getCommands()
+      .then(setCommands)
+      .catch((error) => console.error("Error:", error));
+  }, []);
+
+  const updateSuggestions = (text: string, cursor: number) => {
+    const { start, items } = buildSuggestions(commands, text, cursor);
+    setWordStart(start);
+    setSuggestions(items);
+    setSelected(0);
+  };
+
+  const applySuggestion = (suggestion: Suggestion) => {
+    const textarea = textareaRef.current;
+    if (!textarea) return;
+
+    const cursor = textarea.selectionStart;
+    const newValue = value.slice(0, wordStart) + suggestion.insert + value.slice(cursor);
+    const newCursor = wordStart + suggestion.insert.length;
+    onChange(newValue);
+
+    // Reposicionar el cursor después de que React actualice el valor
+    requestAnimationFrame(() => {
+      textarea.focus();
+      textarea.setSelectionRange(newCursor, newCursor);
+      updateSuggestions(newValue, newCursor);
+    });
+  };
+
+  const handleKeyDown = (e: React.KeyboardEvent<HTMLTextAreaElement>) => {
+    if (suggestions.length === 0) return;
+
+    if (e.key === "ArrowDown") {
+      e.preventDefault();
+      setSelected((selected + 1) % suggestions.length);
+    } else if (e.key === "ArrowUp") {
+      e.preventDefault();
+      setSelected((selected - 1 + suggestions.length) % suggestions.length);
+    } else if (e.key === "Tab" || e.key === "Enter") {
+      e.preventDefault();
+      applySuggestion(suggestions[selected]);
+    } else if (e.key === "Escape") {
+      setSuggestions([]);
+    }
+  };
+
   return (
-    <div className="rounded-lg overflow-hidden shadow-lg border border-gray-200 dark:border-gray-700">
+    <div className="relative rounded-lg overflow-visible shadow-lg border border-gray-200 dark:border-gray-700">
       <div className="bg-gray-100 dark:bg-gray-800 px-4 py-2 border-b border-gray-200 dark:border-gray-700">
         <div className="flex space-x-2">
         </div>
@@ -16,12 +144,40 @@ const InputTerminal = ({ value, onChange }: InputTerminalProps) => {
         </span>
       </div>
       <textarea
+        ref={textareaRef}
         className="w-full h-48 bg-white dark:bg-gray-900 text-gray-800 dark:text-gray-100 p-4 font-mono resize-none focus:outline-none"
         value={value}
-        onChange={(e) => onChange(e.target.value)}
+        onChange={(e) => {
+          onChange(e.target.value);
+          updateSuggestions(e.target.value, e.target.selectionStart);
+        }}
+        onKeyDown={handleKeyDown}
+        onBlur={() => setSuggestions([])}
         placeholder="Ingrese sus comandos aquí..."
         spellCheck="false"
       />
+      {suggestions.length > 0 && (
+        <ul className="absolute left-4 right-4 bottom-2 z-10 max-h-40 overflow-auto rounded border border-gray-300 dark:border-gray-600 bg-white dark:bg-gray-800 font-mono text-sm shadow-lg">
+          {suggestions.map((s, i) => (
+            <li
+              key={s.label}
+              className={`px-3 py-1 cursor-pointer flex justify-between gap-4 ${
+                i === selected ? "bg-blue-100 dark:bg-blue-900" : ""
+              }`}
+              // mousedown para que el textarea no pierda el foco antes de aplicar
+              onMouseDown={(e) => {
+                e.preventDefault();
+                applySuggestion(s);
+              }}
+            >
+              <span>{s.label}</span>
+              <span className="text-xs text-gray-500 dark:text-gray-400 truncate">
+                {s.detail}
+              </span>
+            </li>
+          ))}
+        </ul>
+      )}
     </div>
   );
 };
@@ -1,10 +1,19 @@
-import React from "react";
+import React, { useEffect, useRef } from "react";
 
 interface OutputTerminalProps {
   output: string;
 }
 
 const OutputTerminal = ({ output }: OutputTerminalProps) => {
+  const containerRef = useRef<HTMLDivElement>(null);
+
+  // Mantener visible la última línea mientras llegan resultados en vivo
+  useEffect(() => {
+    if (containerRef.current) {
+      containerRef.current.scrollTop = containerRef.current.scrollHeight;
+    }
+  }, [output]);
+
   return (
     <div className="rounded-lg overflow-hidden shadow-lg border border-gray-200 dark:border-gray-700">
       <div className="bg-gray-100 dark:bg-gray-800 px-4 py-2 border-b border-gray-200 dark:border-gray-700">
@@ -16,7 +25,10 @@ const OutputTerminal = ({ output }: OutputTerminalProps) => {
           </span>
         </div>
       </div>
-      <div className="w-full h-48 bg-white dark:bg-gray-900 text-gray-800 dark:text-gray-100 p-4 font-mono overflow-auto">
+      <div
+        ref={containerRef}
+        className="w-full h-48 bg-white dark:bg-gray-900 text-gray-800 dark:text-gray-100 p-4 font-mono overflow-auto"
+      >
         <pre className="whitespace-pre-wrap">{output}</pre>
       </div>
     </div>
@@ -21,3 +21,102 @@ export const executeCommands = async (command: string): Promise<string> => {
     throw new Error("Error al ejecutar los comandos");
   }
 };
+
+// Resultado de una línea enviado por /execute/stream
+export interface LineEvent {
+  line: number;
+  raw: string;
+  command: string;
+  status: "ok" | "error" | "skipped";
+  success: boolean;
+  output: string;
+  error?: string;
+  durationMs: number;
+}
+
+// Resumen enviado al terminar el script
+export interface StreamSummary {
+  total: number;
+  errors: number;
+}
+
+// Ejecuta el script completo y llama a onLine por cada comando apenas termina.
+// Con dryRun los comandos destructivos solo informan lo que harían.
+export const executeCommandsStream = async (
+  command: string,
+  onLine: (event: LineEvent) => void,
+  dryRun = false
+): Promise<StreamSummary> => {
+  const response = await fetch(`${API_URL}/execute/stream`, {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+    },
+    body: JSON.stringify({ command, dryRun }),
+  });
+
+  if (!response.ok || !response.body) {
+    throw new Error("Error en la respuesta del servidor");
+  }
+
+  const reader = response.body.getReader();
+  const decoder = new TextDecoder();
+  let buffer = "";
+  let summary: StreamSummary = { total: 0, errors: 0 };
+
+  // Los eventos SSE vienen separados por una línea en blanco
+  for (;;) {
+    const { done, value } = await reader.read();
+    if (done) break;
+    buffer += decoder.decode(value, { stream: true });
+
+    let separator = buffer.indexOf("\n\n");
+    while (separator !== -1) {
+      const rawEvent = buffer.slice(0, separator);
+      buffer = buffer.slice(separator + 2);
+
+      let eventName = "message";
+      let data = "";
+      for (const line of rawEvent.split("\n")) {
+        if (line.startsWith("event: ")) eventName = line.slice(7);
+        else if (line.startsWith("data: ")) data += line.slice(6);
+      }
+
+      if (eventName === "line") onLine(JSON.parse(data) as LineEvent);
+      else if (eventName === "done") summary = JSON.parse(data) as StreamSummary;
+
+      separator = buffer.indexOf("\n\n");
+    }
+  }
+
+  return summary;
+};
+
+// Parámetro declarado por un comando en el registro del backend
+export interface ParamInfo {
+  name: string;
+  type: "string" | "int" | "flag" | "path";
+  required: boolean;
+  allowed?: string[];
+  default?: string;
+  numbered: boolean;
+  positional: boolean;
+  description: string;
+}
+
+// Comando registrado en el backend con sus parámetros
+export interface CommandInfo {
+  name: string;
+  description: string;
+  params: ParamInfo[];
+  simulated: boolean;
+}
+
+// Obtiene los comandos disponibles para la ayuda y el autocompletado del editor
+export const getCommands = async (): Promise<CommandInfo[]> => {
+  const response = await fetch(`${API_URL}/commands`);
+  if (!response.ok) {
+    throw new Error("Error al obtener los comandos");
+  }
+  return (await response.json()) as CommandInfo[];
+};